@@ -7,6 +7,7 @@ import (
 	"log"
 
 	"github.com/hive-agi/hive-mcp-cli/internal/hive"
+	"github.com/hive-agi/hive-mcp-cli/internal/setupmcp"
 	"github.com/mark3labs/mcp-go/server"
 	bmcp "github.com/BuddhiLW/bonzai/mcp"
 )
@@ -16,6 +17,9 @@ func main() {
 	// OnlyTagged() ensures only commands with Mcp metadata are exposed
 	s := bmcp.NewServer(hive.Cmd, bmcp.OnlyTagged())
 
+	// Each setup step is also its own tool with a structured result
+	setupmcp.Register(s)
+
 	// Start the server with stdio transport
 	if err := server.ServeStdio(s); err != nil {
 		log.Fatalf("Server error: %v", err)
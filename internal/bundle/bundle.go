@@ -0,0 +1,446 @@
+// Package bundle packs everything hive setup downloads into a single
+// archive so setup can run on machines without network access
+package bundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+	"github.com/hive-agi/hive-mcp-cli/internal/download"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+const (
+	// manifestName is the last entry of every bundle
+	manifestName = "manifest.json"
+
+	bundleFormat = 1
+
+	// Paths inside the bundle
+	repoPath      = "repo/hive-mcp.bundle"
+	m2Path        = "deps/m2"
+	gitlibsPath   = "deps/gitlibs"
+	downloadsPath = "downloads"
+	chromaPath    = "images/chroma.tar"
+	modelsPath    = "ollama/models"
+
+	// DefaultModel is the embedding model setup pulls
+	DefaultModel = "nomic-embed-text"
+)
+
+// Manifest describes the contents of a bundle
+type Manifest struct {
+	Format        int       `json:"format"`
+	CreatedAt     time.Time `json:"created_at"`
+	OS            string    `json:"os"`
+	Arch          string    `json:"arch"`
+	HiveMCPCommit string    `json:"hive_mcp_commit"`
+	ChromaImage   string    `json:"chroma_image,omitempty"`
+	OllamaModels  []string  `json:"ollama_models,omitempty"`
+	Artifacts     []string  `json:"artifacts,omitempty"`
+
+	// Files maps every bundled file to its SHA-256
+	Files map[string]string `json:"files"`
+
+	// Skipped lists components that couldn't be gathered
+	Skipped []string `json:"skipped,omitempty"`
+}
+
+// CreateOptions configures Create
+type CreateOptions struct {
+	Out        string   // archive path (.tar.gz)
+	HiveMCPDir string   // existing checkout to bundle
+	Models     []string // Ollama models, defaults to DefaultModel
+}
+
+// DefaultBundlePath returns a bundle name for this platform in the
+// working directory
+func DefaultBundlePath() string {
+	return fmt.Sprintf("hive-bundle-%s-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH, time.Now().Format("20060102"))
+}
+
+// Create gathers the hive-mcp repository, its Clojure dependencies,
+// the installer artifacts, the Chroma image and Ollama models into a
+// bundle. The repository and dependencies are required; the other
+// components are skipped with a warning when unavailable.
+func Create(opts CreateOptions) (*Manifest, error) {
+	if opts.HiveMCPDir == "" {
+		opts.HiveMCPDir = setup.DefaultHiveMCPDir()
+	}
+	if len(opts.Models) == 0 {
+		opts.Models = []string{DefaultModel}
+	}
+
+	commit, err := exec.Command("git", "-C", opts.HiveMCPDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return nil, fmt.Errorf("no hive-mcp checkout at %s - run hive setup first", opts.HiveMCPDir)
+	}
+
+	tmp, err := os.MkdirTemp("", "hive-bundle-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	// Written beside opts.Out and renamed once complete, so a failed
+	// build never leaves a truncated bundle behind
+	out, err := os.CreateTemp(filepath.Dir(opts.Out), ".hive-bundle-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", opts.Out, err)
+	}
+	defer os.Remove(out.Name())
+	defer out.Close()
+	gz := gzip.NewWriter(out)
+	w := &writer{tw: tar.NewWriter(gz), files: make(map[string]string)}
+
+	m := &Manifest{
+		Format:        bundleFormat,
+		CreatedAt:     time.Now().UTC(),
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		HiveMCPCommit: strings.TrimSpace(string(commit)),
+	}
+	skip := func(component string, err error) {
+		fmt.Printf("  ! skipping %s: %v\n", component, err)
+		m.Skipped = append(m.Skipped, component)
+	}
+
+	fmt.Println("  repository")
+	if err := addRepo(w, opts.HiveMCPDir, tmp); err != nil {
+		return nil, err
+	}
+
+	fmt.Println("  Clojure dependencies (clojure -P)")
+	if err := addDeps(w, opts.HiveMCPDir, tmp); err != nil {
+		return nil, err
+	}
+
+	fmt.Println("  installers")
+	artifacts, err := setup.OfflineArtifacts()
+	if err != nil {
+		skip("installers", err)
+	}
+	for _, name := range artifacts {
+		file, err := download.Fetch(name)
+		if err != nil {
+			skip(name, err)
+			continue
+		}
+		if err := w.addFile(path.Join(downloadsPath, filepath.Base(file)), file); err != nil {
+			return nil, err
+		}
+		m.Artifacts = append(m.Artifacts, name)
+	}
+
+	fmt.Println("  Chroma image")
+	if image, err := addChromaImage(w, opts.HiveMCPDir, tmp); err != nil {
+		skip("chroma image", err)
+	} else {
+		m.ChromaImage = image
+	}
+
+	fmt.Println("  Ollama models")
+	for _, model := range opts.Models {
+		if err := addModel(w, model); err != nil {
+			skip("ollama model "+model, err)
+			continue
+		}
+		m.OllamaModels = append(m.OllamaModels, model)
+	}
+
+	m.Files = w.files
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := w.addBytes(manifestName, data); err != nil {
+		return nil, err
+	}
+
+	if err := w.tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := out.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(out.Name(), 0644); err != nil {
+		return nil, err
+	}
+	return m, os.Rename(out.Name(), opts.Out)
+}
+
+// addRepo adds a git bundle of every branch and tag in the checkout
+func addRepo(w *writer, dir, tmp string) error {
+	file := filepath.Join(tmp, "hive-mcp.bundle")
+	cmd := exec.Command("git", "-C", dir, "bundle", "create", file, "HEAD", "--branches", "--tags")
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git bundle failed: %w", err)
+	}
+	return w.addFile(repoPath, file)
+}
+
+// addDeps resolves the checkout's dependencies into an empty Maven
+// repository and gitlibs directory, so the bundle holds exactly what
+// clojure -P needs
+func addDeps(w *writer, dir, tmp string) error {
+	m2 := filepath.Join(tmp, "m2")
+	gitlibs := filepath.Join(tmp, "gitlibs")
+
+	cmd := exec.Command("clojure", "-Sdeps", fmt.Sprintf("{:mvn/local-repo %q}", m2), "-P")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GITLIBS="+gitlibs)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("clojure -P failed: %w", err)
+	}
+
+	if err := w.addDir(m2Path, m2); err != nil {
+		return err
+	}
+	if _, err := os.Stat(gitlibs); err == nil {
+		return w.addDir(gitlibsPath, gitlibs)
+	}
+	return nil
+}
+
+// addChromaImage saves the Chroma image with docker save, pulling it
+// first if it isn't present locally
+func addChromaImage(w *writer, dir, tmp string) (string, error) {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return "", fmt.Errorf("docker not installed")
+	}
+	image := chroma.Image(dir)
+	if exec.Command("docker", "image", "inspect", image).Run() != nil {
+		pull := exec.Command("docker", "pull", image)
+		pull.Stdout = os.Stdout
+		pull.Stderr = os.Stderr
+		if err := pull.Run(); err != nil {
+			return "", fmt.Errorf("docker pull %s failed: %w", image, err)
+		}
+	}
+
+	file := filepath.Join(tmp, "chroma.tar")
+	if out, err := exec.Command("docker", "save", "-o", file, image).CombinedOutput(); err != nil {
+		return "", fmt.Errorf("docker save failed: %s", strings.TrimSpace(string(out)))
+	}
+	if err := w.addFile(chromaPath, file); err != nil {
+		return "", err
+	}
+	return image, os.Remove(file)
+}
+
+// addModel adds an Ollama model's manifest and blobs from the local
+// model store
+func addModel(w *writer, model string) error {
+	store, manifest, err := findModel(model)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		return err
+	}
+	var parsed struct {
+		Config struct {
+			Digest string `json:"digest"`
+		} `json:"config"`
+		Layers []struct {
+			Digest string `json:"digest"`
+		} `json:"layers"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return fmt.Errorf("invalid manifest %s: %w", manifest, err)
+	}
+
+	rel, _ := filepath.Rel(store, manifest)
+	if err := w.addFile(path.Join(modelsPath, filepath.ToSlash(rel)), manifest); err != nil {
+		return err
+	}
+	digests := []string{parsed.Config.Digest}
+	for _, layer := range parsed.Layers {
+		digests = append(digests, layer.Digest)
+	}
+	for _, digest := range digests {
+		if digest == "" {
+			continue
+		}
+		blob := strings.Replace(digest, ":", "-", 1)
+		if err := w.addFile(path.Join(modelsPath, "blobs", blob), filepath.Join(store, "blobs", blob)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ModelStores returns the Ollama model directories to search: the
+// OLLAMA_MODELS override, the user store and the Linux service store
+func ModelStores() []string {
+	if dir := os.Getenv("OLLAMA_MODELS"); dir != "" {
+		return []string{dir}
+	}
+	home, _ := os.UserHomeDir()
+	return []string{
+		filepath.Join(home, ".ollama", "models"),
+		"/usr/share/ollama/.ollama/models",
+	}
+}
+
+// findModel locates a model's manifest ("name" or "name:tag")
+func findModel(model string) (store, manifest string, err error) {
+	name, tag, ok := strings.Cut(model, ":")
+	if !ok {
+		tag = "latest"
+	}
+	if !strings.Contains(name, "/") {
+		name = "library/" + name
+	}
+	for _, store := range ModelStores() {
+		manifest := filepath.Join(store, "manifests", "registry.ollama.ai", filepath.FromSlash(name), tag)
+		if _, err := os.Stat(manifest); err == nil {
+			return store, manifest, nil
+		}
+	}
+	return "", "", fmt.Errorf("model not found locally - run: ollama pull %s", model)
+}
+
+// writer adds files to the bundle, recording their checksums
+type writer struct {
+	tw    *tar.Writer
+	files map[string]string
+}
+
+func (w *writer) addFile(name, src string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: info.Size(), ModTime: info.ModTime(), Typeflag: tar.TypeReg}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(w.tw, h), f); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	w.files[name] = hex.EncodeToString(h.Sum(nil))
+	return nil
+}
+
+func (w *writer) addBytes(name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now(), Typeflag: tar.TypeReg}
+	if err := w.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+// addDir adds the regular files under dir below prefix
+func (w *writer) addDir(prefix, dir string) error {
+	return filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		return w.addFile(path.Join(prefix, filepath.ToSlash(rel)), p)
+	})
+}
+
+// Extract unpacks a bundle into dir and verifies every file against
+// the manifest. Bundles built for another platform are refused since
+// their installers wouldn't run.
+func Extract(file, dir string) (*Manifest, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := setup.ExtractTarGz(f, dir, 0); err != nil {
+		return nil, fmt.Errorf("failed to unpack %s: %w", file, err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestName))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a hive bundle (no %s)", file, manifestName)
+	}
+	m := &Manifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("invalid bundle manifest: %w", err)
+	}
+	if m.Format > bundleFormat {
+		return nil, fmt.Errorf("bundle format %d is newer than this CLI supports (%d)", m.Format, bundleFormat)
+	}
+	if m.OS != runtime.GOOS || m.Arch != runtime.GOARCH {
+		return nil, fmt.Errorf("bundle is for %s/%s, this machine is %s/%s", m.OS, m.Arch, runtime.GOOS, runtime.GOARCH)
+	}
+
+	names := make([]string, 0, len(m.Files))
+	for name := range m.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		got, err := hashFile(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			return nil, fmt.Errorf("bundle is missing %s", name)
+		}
+		if got != m.Files[name] {
+			return nil, fmt.Errorf("bundle file %s is corrupt (sha256 mismatch)", name)
+		}
+	}
+	return m, nil
+}
+
+// DownloadsDir is the directory in an extracted bundle holding the
+// installer artifacts, usable as a download mirror
+func DownloadsDir(dir string) string {
+	return filepath.Join(dir, downloadsPath)
+}
+
+// RepoBundle is the git bundle of hive-mcp in an extracted bundle
+func RepoBundle(dir string) string {
+	return filepath.Join(dir, filepath.FromSlash(repoPath))
+}
+
+func hashFile(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
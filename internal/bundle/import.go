@@ -0,0 +1,188 @@
+package bundle
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// ImportStep copies an extracted bundle's Clojure dependencies and
+// Ollama models into their usual locations and loads the Chroma image,
+// so the following setup steps find everything locally
+type ImportStep struct {
+	Dir      string // extracted bundle
+	Manifest *Manifest
+}
+
+func (s *ImportStep) Name() string {
+	return "Import offline bundle"
+}
+
+func (s *ImportStep) Check() (bool, error) {
+	// Copies skip files that already exist, so re-running is cheap
+	return false, nil
+}
+
+func (s *ImportStep) Run() error {
+	home, _ := os.UserHomeDir()
+
+	if err := mergeDir(filepath.Join(s.Dir, m2Path), filepath.Join(home, ".m2", "repository")); err != nil {
+		return fmt.Errorf("failed to import Maven dependencies: %w", err)
+	}
+	gitlibs := util.GetEnv("GITLIBS", filepath.Join(home, ".gitlibs"))
+	if err := mergeDir(filepath.Join(s.Dir, gitlibsPath), gitlibs); err != nil {
+		return fmt.Errorf("failed to import git dependencies: %w", err)
+	}
+
+	if len(s.Manifest.OllamaModels) > 0 {
+		store, owner := ImportModelStore()
+		if err := importModels(filepath.Join(s.Dir, modelsPath), store, owner); err != nil {
+			return fmt.Errorf("failed to import Ollama models into %s: %w", store, err)
+		}
+	}
+
+	if s.Manifest.ChromaImage != "" {
+		out, err := exec.Command("docker", "load", "-i", filepath.Join(s.Dir, chromaPath)).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("docker load failed: %s", strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+func (s *ImportStep) Rollback() error {
+	// Imported caches are harmless to keep
+	return nil
+}
+
+// ImportModelStore returns the model directory the installed Ollama
+// reads and the user owning it ("" for the current user). A system
+// ollama.service, as the Linux installer sets up, uses its own user's
+// store rather than ours.
+func ImportModelStore() (store, owner string) {
+	if dir := os.Getenv("OLLAMA_MODELS"); dir != "" {
+		return dir, ""
+	}
+	if store, owner, ok := serviceModelStore(); ok {
+		return store, owner
+	}
+	return ModelStores()[0], ""
+}
+
+// serviceModelStore reads the model store of a system ollama.service
+// from its unit: OLLAMA_MODELS if set, else its user's ~/.ollama/models
+func serviceModelStore() (store, owner string, ok bool) {
+	out, err := exec.Command("systemctl", "show", "ollama.service",
+		"-p", "LoadState", "-p", "User", "-p", "Environment").Output()
+	if err != nil {
+		return "", "", false
+	}
+
+	props := make(map[string]string)
+	for _, line := range strings.Split(string(out), "\n") {
+		if key, value, found := strings.Cut(line, "="); found {
+			props[key] = value
+		}
+	}
+	if props["LoadState"] != "loaded" {
+		return "", "", false
+	}
+
+	owner = props["User"]
+	if owner == "" {
+		owner = "root"
+	}
+	for _, kv := range strings.Fields(props["Environment"]) {
+		if dir, found := strings.CutPrefix(kv, "OLLAMA_MODELS="); found {
+			return dir, owner, true
+		}
+	}
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return "", "", false
+	}
+	return filepath.Join(u.HomeDir, ".ollama", "models"), owner, true
+}
+
+// importModels merges bundled models into store. A store belonging to
+// another user is written as root and handed back to that user.
+func importModels(src, store, owner string) error {
+	if owner == "" {
+		return mergeDir(src, store)
+	}
+	if cur, err := user.Current(); err == nil && cur.Username == owner {
+		return mergeDir(src, store)
+	}
+
+	var cmds [][]string
+	if os.Geteuid() == 0 {
+		if err := mergeDir(src, store); err != nil {
+			return err
+		}
+	} else {
+		cmds = append(cmds,
+			[]string{"sudo", "mkdir", "-p", store},
+			[]string{"sudo", "cp", "-Rn", src + "/.", store + "/"})
+	}
+	chown := []string{"chown", "-R", owner + ":", store}
+	if os.Geteuid() != 0 {
+		chown = append([]string{"sudo"}, chown...)
+	}
+	cmds = append(cmds, chown)
+
+	for _, argv := range cmds {
+		out, err := exec.Command(argv[0], argv[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s failed: %s", strings.Join(argv, " "), strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// mergeDir copies files from src into dest, keeping files that
+// already exist. A missing src is not an error.
+func mergeDir(src, dest string) error {
+	if _, err := os.Stat(src); os.IsNotExist(err) {
+		return nil
+	}
+	return filepath.Walk(src, func(p string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(src, p)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if _, err := os.Stat(target); err == nil {
+			return nil
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+		return copyFile(p, target)
+	})
+}
+
+func copyFile(src, dest string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,442 @@
+package chroma
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// metadataName is the first entry of every backup archive
+	metadataName = "hive-backup.json"
+
+	// dataPrefix holds the volume contents inside the archive
+	dataPrefix = "data/"
+
+	backupFormat = 1
+)
+
+// Metadata describes a backup archive
+type Metadata struct {
+	Format        int       `json:"format"`
+	CreatedAt     time.Time `json:"created_at"`
+	Volume        string    `json:"volume"`
+	Image         string    `json:"image,omitempty"`
+	ChromaVersion string    `json:"chroma_version,omitempty"`
+}
+
+// BackupOptions configures Backup
+type BackupOptions struct {
+	Out    string // archive path (.tar.zst, .tar.gz or .tar)
+	Volume string // defaults to DefaultVolume
+}
+
+// RestoreOptions configures Restore
+type RestoreOptions struct {
+	File   string
+	Volume string // defaults to the volume recorded in the backup
+	Force  bool   // restore across incompatible Chroma versions
+}
+
+// DefaultBackupPath returns a timestamped archive name in the working
+// directory, falling back to gzip when zstd isn't installed
+func DefaultBackupPath() string {
+	ext := ".tar.zst"
+	if _, err := exec.LookPath("zstd"); err != nil {
+		ext = ".tar.gz"
+	}
+	return "chroma-backup-" + time.Now().Format("20060102-150405") + ext
+}
+
+// Backup stops Chroma, archives its data volume with version metadata
+// and starts Chroma again
+func Backup(opts BackupOptions) (*Metadata, error) {
+	vol, err := ResolveVolume(opts.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	out := opts.Out
+	if out == "" {
+		out = DefaultBackupPath()
+	}
+
+	meta := &Metadata{
+		Format:    backupFormat,
+		CreatedAt: time.Now().UTC(),
+		Volume:    vol,
+	}
+	// Query the version before stopping the server
+	meta.ChromaVersion, meta.Image = CurrentVersion(vol)
+
+	stopped, err := stopContainers(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeArchive(out, vol, meta); err != nil {
+		startContainers(stopped)
+		return nil, err
+	}
+
+	if err := startContainers(stopped); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+// writeArchive streams the volume through the helper container into
+// out, writing to a temp file first so a failed backup never leaves a
+// truncated archive behind
+func writeArchive(out, volume string, meta *Metadata) error {
+	tmp, err := os.CreateTemp(filepath.Dir(out), ".chroma-backup-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", out, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	cw, err := compressWriter(tmp, out)
+	if err != nil {
+		return err
+	}
+
+	tw := tar.NewWriter(cw)
+	metaJSON, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    metadataName,
+		Mode:    0644,
+		Size:    int64(len(metaJSON)),
+		ModTime: meta.CreatedAt,
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(metaJSON); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("docker", "run", "--rm",
+		"-v", volume+":/data:ro",
+		helperImage, "tar", "-C", "/data", "-cf", "-", ".")
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start helper container: %w", err)
+	}
+
+	copyErr := copyEntries(tar.NewReader(stdout), tw, func(name string) string {
+		return dataPrefix + name
+	})
+	if copyErr != nil {
+		cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && copyErr == nil {
+		return fmt.Errorf("helper container failed: %w", err)
+	}
+	if copyErr != nil {
+		return fmt.Errorf("failed to archive volume: %w", copyErr)
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := cw.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), out)
+}
+
+// ReadMetadata returns the metadata of a backup archive
+func ReadMetadata(file string) (*Metadata, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompressReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return readMetadata(tar.NewReader(r))
+}
+
+func readMetadata(tr *tar.Reader) (*Metadata, error) {
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != metadataName {
+		return nil, fmt.Errorf("not a hive Chroma backup (missing %s)", metadataName)
+	}
+
+	var meta Metadata
+	if err := json.NewDecoder(tr).Decode(&meta); err != nil {
+		return nil, fmt.Errorf("invalid backup metadata: %w", err)
+	}
+	if meta.Format > backupFormat {
+		return nil, fmt.Errorf("backup format %d is newer than this CLI supports", meta.Format)
+	}
+	return &meta, nil
+}
+
+// Restore replaces the contents of the Chroma data volume with a
+// backup. It refuses to restore across incompatible Chroma versions
+// unless Force is set.
+func Restore(opts RestoreOptions) (*Metadata, error) {
+	f, err := os.Open(opts.File)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := decompressReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	tr := tar.NewReader(r)
+	meta, err := readMetadata(tr)
+	if err != nil {
+		return nil, err
+	}
+
+	vol := opts.Volume
+	if vol == "" {
+		if existing, err := ResolveVolume(""); err == nil {
+			vol = existing
+		} else {
+			vol = meta.Volume
+		}
+	}
+
+	current, _ := CurrentVersion(vol)
+	if err := CheckCompatible(meta.ChromaVersion, current); err != nil {
+		if !opts.Force {
+			return nil, fmt.Errorf("%w (use --force to restore anyway)", err)
+		}
+		fmt.Printf("Warning: %v, restoring anyway\n", err)
+	} else if meta.ChromaVersion == "" || current == "" {
+		fmt.Println("Warning: could not determine Chroma versions, skipping compatibility check")
+	}
+
+	stopped, err := stopContainers(vol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := extractArchive(tr, vol); err != nil {
+		startContainers(stopped)
+		return nil, err
+	}
+
+	if err := startContainers(stopped); err != nil {
+		return meta, err
+	}
+	return meta, nil
+}
+
+// stagingDir is where a restore is unpacked inside the volume before
+// it replaces the current data
+const stagingDir = "/data/.hive-restore"
+
+// extractArchive unpacks the data entries into a staging directory in
+// the volume, then swaps them in. The current data is only removed once
+// the whole archive has been read and unpacked.
+func extractArchive(tr *tar.Reader, volume string) error {
+	cmd := exec.Command("docker", "run", "--rm", "-i",
+		"-v", volume+":/data",
+		helperImage, "sh", "-c",
+		"rm -rf "+stagingDir+" && mkdir "+stagingDir+" && tar -C "+stagingDir+" -xf -")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start helper container: %w", err)
+	}
+
+	tw := tar.NewWriter(stdin)
+	copyErr := copyEntries(tr, tw, func(name string) string {
+		if !strings.HasPrefix(name, dataPrefix) {
+			return ""
+		}
+		return strings.TrimPrefix(name, dataPrefix)
+	})
+	if copyErr == nil {
+		copyErr = tw.Close()
+	}
+	stdin.Close()
+
+	waitErr := cmd.Wait()
+	if copyErr != nil || waitErr != nil {
+		// Leave the current data untouched
+		runHelper(volume, "rm -rf "+stagingDir)
+		if copyErr != nil {
+			return fmt.Errorf("failed to restore volume: %w", copyErr)
+		}
+		return fmt.Errorf("helper container failed: %w", waitErr)
+	}
+
+	swap := "find /data -mindepth 1 -maxdepth 1 ! -path " + stagingDir + " -exec rm -rf {} + && " +
+		"find " + stagingDir + " -mindepth 1 -maxdepth 1 -exec mv {} /data/ \\; && " +
+		"rmdir " + stagingDir
+	if err := runHelper(volume, swap); err != nil {
+		return fmt.Errorf("failed to swap in restored data (staged copy left in %s): %w", stagingDir, err)
+	}
+	return nil
+}
+
+// runHelper runs a shell script in the helper container with volume
+// mounted at /data
+func runHelper(volume, script string) error {
+	cmd := exec.Command("docker", "run", "--rm",
+		"-v", volume+":/data",
+		helperImage, "sh", "-c", script)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// copyEntries copies tar entries from tr to tw, renaming each with
+// rename. Entries renamed to "" are skipped; entries escaping the
+// archive root are rejected.
+func copyEntries(tr *tar.Reader, tw *tar.Writer, rename func(string) string) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := path.Clean(strings.TrimPrefix(hdr.Name, "./"))
+		if name == "." || name == dataPrefix[:len(dataPrefix)-1] {
+			continue
+		}
+		if name == ".." || strings.HasPrefix(name, "../") || path.IsAbs(name) {
+			return fmt.Errorf("unsafe path in archive: %s", hdr.Name)
+		}
+		if hdr.Typeflag == tar.TypeDir {
+			name += "/"
+		}
+
+		name = rename(name)
+		if name == "" {
+			continue
+		}
+		hdr.Name = name
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := io.Copy(tw, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// compressWriter wraps w with the compression implied by name's extension
+func compressWriter(w io.Writer, name string) (io.WriteCloser, error) {
+	switch {
+	case strings.HasSuffix(name, ".zst"):
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return nil, fmt.Errorf("zstd not found - install it or use a .tar.gz output")
+		}
+		cmd := exec.Command("zstd", "-q", "-c")
+		cmd.Stdout = w
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return &cmdWriteCloser{WriteCloser: stdin, cmd: cmd}, nil
+	case strings.HasSuffix(name, ".gz") || strings.HasSuffix(name, ".tgz"):
+		return gzip.NewWriter(w), nil
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// decompressReader detects zstd or gzip compression from the magic bytes
+func decompressReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return nil, fmt.Errorf("zstd not found - required to read this backup")
+		}
+		cmd := exec.Command("zstd", "-q", "-d", "-c")
+		cmd.Stdin = br
+		cmd.Stderr = os.Stderr
+		stdout, err := cmd.StdoutPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := cmd.Start(); err != nil {
+			return nil, fmt.Errorf("failed to start zstd: %w", err)
+		}
+		return &cmdReadCloser{ReadCloser: stdout, cmd: cmd}, nil
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		return gzip.NewReader(br)
+	default:
+		return io.NopCloser(br), nil
+	}
+}
+
+// cmdWriteCloser closes a command's stdin and waits for it to exit
+type cmdWriteCloser struct {
+	io.WriteCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdWriteCloser) Close() error {
+	if err := c.WriteCloser.Close(); err != nil {
+		return err
+	}
+	return c.cmd.Wait()
+}
+
+// cmdReadCloser stops a command once its output is no longer needed
+type cmdReadCloser struct {
+	io.ReadCloser
+	cmd *exec.Cmd
+}
+
+func (c *cmdReadCloser) Close() error {
+	c.ReadCloser.Close()
+	c.cmd.Process.Kill()
+	c.cmd.Wait()
+	return nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
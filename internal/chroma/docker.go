@@ -0,0 +1,177 @@
+// Package chroma manages the Chroma vector database data volume
+package chroma
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+const (
+	// DefaultVolume is the Docker volume holding Chroma's persistent data
+	DefaultVolume = "chroma-data"
+
+	// DefaultImage is the Chroma release run when the hive-mcp
+	// checkout's compose file doesn't pin one
+	DefaultImage = "chromadb/chroma:1.0.12"
+
+	// helperImage runs tar against the volume so no host paths are needed
+	helperImage = "busybox"
+
+	versionURL = "http://localhost:8000/api/v2/version"
+)
+
+// Image returns the Chroma image setup runs: the one the compose file
+// in the hive-mcp checkout at dir uses for the chroma service, or
+// DefaultImage when it can't be read or names no tag
+func Image(dir string) string {
+	cmd := exec.Command("docker", "compose", "config", "--images", "chroma")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return DefaultImage
+	}
+	image := strings.TrimSpace(strings.Split(string(out), "\n")[0])
+	name := image[strings.LastIndex(image, "/")+1:]
+	if _, tag, ok := strings.Cut(name, ":"); !ok || tag == "latest" {
+		return DefaultImage
+	}
+	return image
+}
+
+// ResolveVolume finds the Docker volume holding Chroma data.
+// docker compose prefixes volumes with the project name, so
+// "hive-mcp_chroma-data" is accepted when "chroma-data" doesn't exist.
+func ResolveVolume(name string) (string, error) {
+	if name == "" {
+		name = DefaultVolume
+	}
+
+	out, err := exec.Command("docker", "volume", "ls", "-q", "--filter", "name="+name).Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to list docker volumes: %w", err)
+	}
+
+	var suffixed []string
+	for _, vol := range strings.Fields(string(out)) {
+		if vol == name {
+			return vol, nil
+		}
+		if strings.HasSuffix(vol, "_"+name) {
+			suffixed = append(suffixed, vol)
+		}
+	}
+
+	switch len(suffixed) {
+	case 0:
+		return "", fmt.Errorf("docker volume %s not found", name)
+	case 1:
+		return suffixed[0], nil
+	default:
+		return "", fmt.Errorf("multiple volumes match %s (%s) - pass one explicitly", name, strings.Join(suffixed, ", "))
+	}
+}
+
+// containersUsing returns the IDs of containers mounting the volume
+func containersUsing(volume string, all bool) ([]string, error) {
+	args := []string{"ps", "-q", "--filter", "volume=" + volume}
+	if all {
+		args = append(args, "-a")
+	}
+	out, err := exec.Command("docker", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	return strings.Fields(string(out)), nil
+}
+
+// stopContainers stops running containers that use the volume and
+// returns their IDs so they can be started again afterwards
+func stopContainers(volume string) ([]string, error) {
+	ids, err := containersUsing(volume, false)
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	cmd := exec.Command("docker", append([]string{"stop"}, ids...)...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("failed to stop Chroma container: %w", err)
+	}
+	return ids, nil
+}
+
+// startContainers restarts containers stopped by stopContainers
+func startContainers(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	cmd := exec.Command("docker", append([]string{"start"}, ids...)...)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to restart Chroma container: %w", err)
+	}
+	return nil
+}
+
+// CurrentVersion reports the installed Chroma version and image.
+// The running server is asked first; otherwise the version is taken
+// from the image tag of a container using the volume.
+func CurrentVersion(volume string) (version, image string) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(versionURL)
+	if err == nil {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			version = strings.Trim(strings.TrimSpace(string(body)), `"`)
+		}
+	}
+
+	ids, err := containersUsing(volume, true)
+	if err == nil && len(ids) > 0 {
+		out, err := exec.Command("docker", "inspect", "-f", "{{.Config.Image}}", ids[0]).Output()
+		if err == nil {
+			image = strings.TrimSpace(string(out))
+		}
+	}
+
+	if version == "" && image != "" {
+		if i := strings.LastIndex(image, ":"); i >= 0 {
+			tag := image[i+1:]
+			if len(util.ParseVersion(tag)) >= 2 {
+				version = strings.TrimPrefix(tag, "v")
+			}
+		}
+	}
+
+	return version, image
+}
+
+// CheckCompatible returns an error if data written by backupVersion
+// can't safely be opened by currentVersion. Chroma changes its
+// on-disk format between minor releases before 1.0 and between major
+// releases after, and never supports downgrades. Unknown versions
+// are treated as compatible; callers should warn about them.
+func CheckCompatible(backupVersion, currentVersion string) error {
+	b := util.ParseVersion(backupVersion)
+	c := util.ParseVersion(currentVersion)
+	if len(b) < 2 || len(c) < 2 {
+		return nil
+	}
+
+	if b[0] != c[0] || (b[0] == 0 && b[1] != c[1]) {
+		return fmt.Errorf("backup is from Chroma %s but Chroma %s is installed", backupVersion, currentVersion)
+	}
+	if util.CompareVersions(backupVersion, currentVersion) > 0 {
+		return fmt.Errorf("backup is from newer Chroma %s than installed %s", backupVersion, currentVersion)
+	}
+	return nil
+}
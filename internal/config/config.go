@@ -0,0 +1,135 @@
+// Package config loads the user configuration for hive-mcp-cli
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the contents of ~/.config/hive/config.yaml
+type Config struct {
+	Repo  Repo  `yaml:"repo,omitempty"`
+	Emacs Emacs `yaml:"emacs,omitempty"`
+
+	// Env holds extra environment variables for hive-mcp processes
+	// (for example the systemd units written by install-units)
+	Env map[string]string `yaml:"env,omitempty"`
+
+	Downloads Downloads `yaml:"downloads,omitempty"`
+	Deps      Deps      `yaml:"deps,omitempty"`
+	Shell     Shell     `yaml:"shell,omitempty"`
+	Secrets   Secrets   `yaml:"secrets,omitempty"`
+
+	OpenRouter OpenRouter `yaml:"openrouter,omitempty"`
+}
+
+// Repo selects the hive-mcp repository and revision setup installs
+type Repo struct {
+	// URL is an HTTPS or SSH remote or a local path; empty means
+	// upstream hive-mcp
+	URL string `yaml:"url,omitempty"`
+
+	// Ref is a tag, branch or commit SHA; empty means the default branch
+	Ref string `yaml:"ref,omitempty"`
+
+	// Depth makes a shallow clone of that many commits
+	Depth int `yaml:"depth,omitempty"`
+}
+
+// Emacs configures how the CLI talks to the Emacs server
+type Emacs struct {
+	// ServerName is the daemon's server name (emacs --daemon=NAME) or
+	// an absolute socket path. Empty means Emacs' default server.
+	ServerName string `yaml:"server_name,omitempty"`
+}
+
+// Downloads configures how setup fetches pinned release tarballs
+type Downloads struct {
+	// Mirror is a local directory searched for artifacts (by file name)
+	// before the network, e.g. for air-gapped hosts or test fixtures
+	Mirror string `yaml:"mirror,omitempty"`
+
+	// Checksums maps artifact names to SHA-256 digests, overriding the
+	// checksums built into the CLI
+	Checksums map[string]string `yaml:"checksums,omitempty"`
+}
+
+// Deps configures how setup prepares hive-mcp's Clojure dependencies
+type Deps struct {
+	// Aliases are deps.edn aliases prepared in addition to the default
+	// classpath, written as "dev" or ":dev:test"
+	Aliases []string `yaml:"aliases,omitempty"`
+}
+
+// Shell configures the managed block setup writes to shell configs
+type Shell struct {
+	// Mode is "literal" (the default) to write the variables' values,
+	// or "eval" to write a single line that loads 'hive env'
+	Mode string `yaml:"mode,omitempty"`
+}
+
+// Secrets configures where 'hive secrets' keeps credentials
+type Secrets struct {
+	// Backend is "file" (the default, an age-encrypted file), "pass"
+	// or "secret-service"
+	Backend string `yaml:"backend,omitempty"`
+
+	// KeyFile is the age identity for the file backend; empty means
+	// secrets.key next to this config, created on first use
+	KeyFile string `yaml:"key_file,omitempty"`
+
+	// Passphrase encrypts the file backend with a passphrase, read from
+	// HIVE_SECRETS_PASSPHRASE or prompted for, instead of a key file
+	Passphrase bool `yaml:"passphrase,omitempty"`
+}
+
+// OpenRouter configures how doctor validates OPENROUTER_API_KEY
+type OpenRouter struct {
+	// BaseURL is the API base; empty means https://openrouter.ai/api/v1.
+	// HIVE_OPENROUTER_URL overrides it.
+	BaseURL string `yaml:"base_url,omitempty"`
+
+	// Validate makes every doctor run check the key against the API,
+	// as with 'hive doctor --validate-keys'
+	Validate bool `yaml:"validate,omitempty"`
+}
+
+// Dir returns the configuration directory
+// ($XDG_CONFIG_HOME/hive, defaulting to ~/.config/hive)
+func Dir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "hive")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "hive")
+}
+
+// Path returns the configuration file path, overridable with HIVE_CONFIG
+func Path() string {
+	if path := os.Getenv("HIVE_CONFIG"); path != "" {
+		return path
+	}
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+// Load reads the configuration file. A missing file yields an empty
+// configuration so every setting falls back to its default.
+func Load() (*Config, error) {
+	cfg := &Config{}
+
+	data, err := os.ReadFile(Path())
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", Path(), err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", Path(), err)
+	}
+	return cfg, nil
+}
@@ -3,6 +3,9 @@ package detect
 import (
 	"fmt"
 	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
 )
 
 // Status represents the state of a check
@@ -52,6 +55,13 @@ type DetectionResult struct {
 	Prereqs  []PrereqCheck
 	Services []ServiceCheck
 	EnvVars  []EnvVarCheck
+
+	// EmacsSockets lists the Emacs server sockets found on disk
+	EmacsSockets []emacs.Socket
+
+	// VersionManagers lists mise/asdf/sdkman installs and the tools
+	// they provide in the hive-mcp directory
+	VersionManagers []VersionManager
 }
 
 // Summary returns a summary of all checks
@@ -98,9 +108,11 @@ func Run() (*DetectionResult, error) {
 
 	// Check prerequisites
 	result.Prereqs = CheckAllPrereqs()
+	result.VersionManagers = DetectVersionManagers(hiveMCPDir())
 
 	// Check services
 	result.Services = CheckAllServices()
+	result.EmacsSockets = emacs.Sockets()
 
 	// Check environment variables
 	result.EnvVars = CheckAllEnvVars()
@@ -146,6 +158,25 @@ func PrintResult(r *DetectionResult, colorize func(Status, string) string) {
 		}
 	}
 
+	// Version managers
+	if len(r.VersionManagers) > 0 {
+		fmt.Println("\nVersion Managers:")
+		for _, m := range r.VersionManagers {
+			fmt.Printf("  %s (%s)\n", m.Name, m.Path)
+			for _, t := range m.Tools {
+				installed := "none installed"
+				if len(t.Installed) > 0 {
+					installed = "installed: " + strings.Join(t.Installed, ", ")
+				}
+				if t.Active != "" {
+					fmt.Printf("    %s: %s active via %s (%s)\n", t.Plugin, t.Active, t.Source, installed)
+				} else {
+					fmt.Printf("    %s: not active (%s)\n", t.Plugin, installed)
+				}
+			}
+		}
+	}
+
 	// Services
 	fmt.Println("\nServices:")
 	for _, s := range r.Services {
@@ -158,18 +189,31 @@ func PrintResult(r *DetectionResult, colorize func(Status, string) string) {
 			fmt.Println()
 		} else {
 			fmt.Printf("  %s %s: not running\n", status, s.Name)
+			if s.Message != "" {
+				fmt.Printf("    %s\n", s.Message)
+			}
+		}
+	}
+	for _, sock := range r.EmacsSockets {
+		state := "live"
+		if !sock.Live {
+			state = "stale"
 		}
+		fmt.Printf("    Emacs socket: %s (%s, %s)\n", sock.Name, sock.Path, state)
 	}
 
 	// Environment Variables
 	fmt.Println("\nEnvironment Variables:")
 	for _, e := range r.EnvVars {
 		status := colorize(e.Status, e.Status.Symbol())
-		if e.Status == StatusOK {
-			val := e.Value
-			if e.Sensitive && len(val) > 8 {
-				val = val[:4] + "****" + val[len(val)-4:]
-			}
+		val := e.Value
+		if e.Sensitive {
+			val = MaskValue(val)
+		}
+		if e.Problem != nil {
+			fmt.Printf("  %s %s: %s\n", status, e.Name, e.Problem.Reason)
+			fmt.Printf("    Fix: %s\n", e.Problem.Fix)
+		} else if e.Status == StatusOK {
 			fmt.Printf("  %s %s: %s\n", status, e.Name, val)
 		} else if e.Required {
 			fmt.Printf("  %s %s: not set (required)\n", status, e.Name)
@@ -191,3 +235,8 @@ func PrintResult(r *DetectionResult, colorize func(Status, string) string) {
 	}
 }
 
+
+// hiveMCPDir returns the hive-mcp checkout version files are read from
+func hiveMCPDir() string {
+	return util.ExpandPath(util.GetEnv("HIVE_MCP_DIR", "~/hive-mcp"))
+}
@@ -1,7 +1,13 @@
 package detect
 
 import (
+	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
 )
 
 // EnvVarCheck contains the result of an environment variable check
@@ -11,53 +17,226 @@ type EnvVarCheck struct {
 	Value     string
 	Required  bool
 	Sensitive bool // mask value in output
+
+	// Problem explains why a set value is invalid; nil when it's valid
+	// or unset
+	Problem *EnvProblem
 }
 
-// envVarSpec defines an environment variable to check
-type envVarSpec struct {
-	name      string
-	required  bool
-	sensitive bool
+// EnvProblem is why a value failed validation and how to fix it
+type EnvProblem struct {
+	Reason string
+	Fix    string
 }
 
-var envVars = []envVarSpec{
-	{name: "HIVE_MCP_DIR", required: true, sensitive: false},
-	{name: "BB_MCP_DIR", required: true, sensitive: false},
-	{name: "OPENROUTER_API_KEY", required: false, sensitive: true},
-	{name: "HOME", required: true, sensitive: false},
-	{name: "SHELL", required: true, sensitive: false},
+func (p *EnvProblem) Error() string {
+	return p.Reason
+}
+
+// EnvVarSpec defines an environment variable and what a valid value
+// looks like
+type EnvVarSpec struct {
+	Name      string
+	Required  bool
+	Sensitive bool
+
+	// IfSet marks overrides that are fine to leave unset; they are only
+	// validated when present
+	IfSet bool
+
+	// Doctor marks the variables hive doctor reports on; the rest are
+	// covered by detect alone
+	Doctor bool
+
+	// Validate returns nil for a good value
+	Validate func(value string) *EnvProblem
+}
+
+// EnvVarSpecs returns the environment variables hive-mcp relies on
+func EnvVarSpecs() []EnvVarSpec {
+	return []EnvVarSpec{
+		{Name: "HIVE_MCP_DIR", Required: true, Doctor: true, Validate: hiveMCPCheckout},
+		{Name: "BB_MCP_DIR", Required: true, Doctor: true, Validate: bbMCPDir},
+		{Name: "OPENROUTER_API_KEY", Sensitive: true, Doctor: true, Validate: apiKey("sk-or-", "OpenRouter")},
+		{Name: "HIVE_OPENROUTER_URL", IfSet: true, Doctor: true, Validate: httpURL},
+		{Name: "HOME", Required: true, Validate: writableDir},
+		{Name: "SHELL", Required: true, Validate: executable},
+	}
 }
 
 // CheckAllEnvVars checks all environment variables
 func CheckAllEnvVars() []EnvVarCheck {
-	results := make([]EnvVarCheck, 0, len(envVars))
-	for _, spec := range envVars {
-		results = append(results, checkEnvVar(spec))
+	specs := EnvVarSpecs()
+	results := make([]EnvVarCheck, 0, len(specs))
+	for _, spec := range specs {
+		if spec.IfSet && os.Getenv(spec.Name) == "" {
+			continue
+		}
+		results = append(results, CheckEnvVar(spec))
 	}
 	return results
 }
 
-func checkEnvVar(spec envVarSpec) EnvVarCheck {
+// CheckEnvVar checks that spec's variable is set and valid
+func CheckEnvVar(spec EnvVarSpec) EnvVarCheck {
 	check := EnvVarCheck{
-		Name:      spec.name,
-		Required:  spec.required,
-		Sensitive: spec.sensitive,
+		Name:      spec.Name,
+		Required:  spec.Required,
+		Sensitive: spec.Sensitive,
 	}
 
-	value := os.Getenv(spec.name)
+	value := os.Getenv(spec.Name)
 	check.Value = value
 
-	if value != "" {
-		check.Status = StatusOK
-	} else if spec.required {
+	switch {
+	case value == "" && spec.Required:
 		check.Status = StatusMissing
-	} else {
+	case value == "":
 		check.Status = StatusWarning
+	default:
+		if spec.Validate != nil {
+			check.Problem = spec.Validate(value)
+		}
+		switch {
+		case check.Problem == nil:
+			check.Status = StatusOK
+		case spec.Required:
+			check.Status = StatusError
+		default:
+			check.Status = StatusWarning
+		}
 	}
 
 	return check
 }
 
+// MaskValue hides all but the ends of a sensitive value
+func MaskValue(value string) string {
+	if len(value) > 8 {
+		return value[:4] + "****" + value[len(value)-4:]
+	}
+	return "****"
+}
+
+// existingDir checks that path is a directory
+func existingDir(path string) *EnvProblem {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return &EnvProblem{
+			Reason: path + " does not exist",
+			Fix:    "Create it, or point the variable at an existing directory",
+		}
+	case err != nil:
+		return &EnvProblem{Reason: err.Error(), Fix: "Check the directory's permissions"}
+	case !info.IsDir():
+		return &EnvProblem{
+			Reason: path + " is not a directory",
+			Fix:    "Point the variable at a directory",
+		}
+	}
+	return nil
+}
+
+// writableDir checks that path is a directory the user can write to
+func writableDir(path string) *EnvProblem {
+	if p := existingDir(path); p != nil {
+		return p
+	}
+	if unix.Access(path, unix.W_OK) != nil {
+		return &EnvProblem{
+			Reason: path + " is not writable",
+			Fix:    fmt.Sprintf("Fix its ownership: sudo chown -R %s %s", os.Getenv("USER"), path),
+		}
+	}
+	return nil
+}
+
+// hiveMCPCheckout checks for a writable git checkout of hive-mcp
+func hiveMCPCheckout(path string) *EnvProblem {
+	if p := existingDir(path); p != nil {
+		p.Fix = "Run 'hive setup' to clone hive-mcp, or export HIVE_MCP_DIR=<your checkout>"
+		return p
+	}
+	if !fileExists(filepath.Join(path, ".git")) {
+		return &EnvProblem{
+			Reason: path + " is not a git checkout",
+			Fix:    "Point HIVE_MCP_DIR at a clone of hive-mcp, or run 'hive setup' to clone one",
+		}
+	}
+	if !fileExists(filepath.Join(path, "bb.edn")) && !fileExists(filepath.Join(path, "deps.edn")) {
+		return &EnvProblem{
+			Reason: path + " has no bb.edn or deps.edn, so it isn't hive-mcp",
+			Fix:    "Point HIVE_MCP_DIR at the hive-mcp checkout (the directory with deps.edn)",
+		}
+	}
+	return writableDir(path)
+}
+
+// bbMCPDir checks for the directory whose bb.edn the MCP server is
+// started from (bb -cp $BB_MCP_DIR/bb.edn)
+func bbMCPDir(path string) *EnvProblem {
+	if p := existingDir(path); p != nil {
+		p.Fix = "export BB_MCP_DIR=$HIVE_MCP_DIR (setup points it at the hive-mcp checkout)"
+		return p
+	}
+	if !fileExists(filepath.Join(path, "bb.edn")) {
+		return &EnvProblem{
+			Reason: path + " has no bb.edn for the MCP server",
+			Fix:    "export BB_MCP_DIR=$HIVE_MCP_DIR (setup points it at the hive-mcp checkout)",
+		}
+	}
+	return nil
+}
+
+// apiKey checks a provider key's format without revealing it
+func apiKey(prefix, provider string) func(string) *EnvProblem {
+	return func(key string) *EnvProblem {
+		if strings.TrimSpace(key) != key || strings.ContainsAny(key, " \t\n\"'") {
+			return &EnvProblem{
+				Reason: "contains whitespace or quotes, probably from copy and paste",
+				Fix:    "Store the key again without surrounding spaces or quotes",
+			}
+		}
+		if !strings.HasPrefix(key, prefix) {
+			return &EnvProblem{
+				Reason: fmt.Sprintf("doesn't start with %s like %s keys do", prefix, provider),
+				Fix:    fmt.Sprintf("Copy the full %s key, starting with %s", provider, prefix),
+			}
+		}
+		return nil
+	}
+}
+
+// httpURL checks for an absolute http(s) URL
+func httpURL(value string) *EnvProblem {
+	u, err := url.Parse(value)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return &EnvProblem{
+			Reason: fmt.Sprintf("%q is not an http(s) URL", value),
+			Fix:    "Use a full URL such as http://localhost:8080/api/v1, or unset it",
+		}
+	}
+	return nil
+}
+
+// executable checks that path is an executable file
+func executable(path string) *EnvProblem {
+	if !fileExists(path) {
+		return &EnvProblem{
+			Reason: path + " does not exist",
+			Fix:    "Set SHELL to your login shell, e.g. chsh -s $(command -v bash)",
+		}
+	}
+	if !isExecutable(path) {
+		return &EnvProblem{
+			Reason: path + " is not executable",
+			Fix:    "Set SHELL to your login shell, e.g. chsh -s $(command -v bash)",
+		}
+	}
+	return nil
+}
+
 // Helper functions used across the package
 
 // getEnv returns the value of an environment variable or a default
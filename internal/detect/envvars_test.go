@@ -0,0 +1,134 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEnvVarValidators(t *testing.T) {
+	dir := t.TempDir()
+	// mkdir makes a directory holding the named entries; ".git" is
+	// made as a directory, the rest as empty files
+	mkdir := func(name string, entries ...string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range entries {
+			var err error
+			if e == ".git" {
+				err = os.Mkdir(filepath.Join(path, e), 0o755)
+			} else {
+				err = os.WriteFile(filepath.Join(path, e), nil, 0o644)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return path
+	}
+	checkout := mkdir("hive-mcp", ".git", "bb.edn", "deps.edn")
+	depsOnly := mkdir("deps-only", ".git", "deps.edn")
+	notGit := mkdir("not-git", "bb.edn")
+	bare := mkdir("bare", ".git")
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "shell")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+	openRouter := apiKey("sk-or-", "OpenRouter")
+
+	tests := []struct {
+		name     string
+		validate func(string) *EnvProblem
+		value    string
+		ok       bool
+	}{
+		{"checkout", hiveMCPCheckout, checkout, true},
+		{"checkout with deps.edn only", hiveMCPCheckout, depsOnly, true},
+		{"checkout missing", hiveMCPCheckout, missing, false},
+		{"checkout is a file", hiveMCPCheckout, file, false},
+		{"checkout not git", hiveMCPCheckout, notGit, false},
+		{"checkout without bb.edn or deps.edn", hiveMCPCheckout, bare, false},
+
+		{"bb dir", bbMCPDir, checkout, true},
+		{"bb dir missing", bbMCPDir, missing, false},
+		{"bb dir without bb.edn", bbMCPDir, depsOnly, false},
+
+		{"key", openRouter, "sk-or-v1-abc123", true},
+		{"key wrong prefix", openRouter, "sk-ant-abc123", false},
+		{"key double quoted", openRouter, `"sk-or-v1-abc123"`, false},
+		{"key single quoted", openRouter, "'sk-or-v1-abc123'", false},
+		{"key trailing newline", openRouter, "sk-or-v1-abc123\n", false},
+		{"key leading space", openRouter, " sk-or-v1-abc123", false},
+
+		{"url", httpURL, "http://localhost:8080/api/v1", true},
+		{"url https", httpURL, "https://openrouter.ai/api/v1", true},
+		{"url without scheme", httpURL, "openrouter.ai/api/v1", false},
+		{"url wrong scheme", httpURL, "ftp://openrouter.ai", false},
+		{"url without host", httpURL, "http://", false},
+		{"url unparsable", httpURL, "http://[::1", false},
+
+		{"writable dir", writableDir, dir, true},
+		{"writable dir missing", writableDir, missing, false},
+		{"writable dir is a file", writableDir, file, false},
+
+		{"shell", executable, script, true},
+		{"shell missing", executable, missing, false},
+		{"shell not executable", executable, file, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := tt.validate(tt.value)
+			if tt.ok && p != nil {
+				t.Fatalf("%q: unexpected problem %q", tt.value, p.Reason)
+			}
+			if !tt.ok {
+				if p == nil {
+					t.Fatalf("%q: accepted", tt.value)
+				}
+				if p.Reason == "" || p.Fix == "" {
+					t.Errorf("%q: problem %+v lacks a reason or fix", tt.value, p)
+				}
+			}
+		})
+	}
+}
+
+// Root can write anywhere, so this only runs as a regular user
+func TestWritableDirReadOnly(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("running as root")
+	}
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod(dir, 0o755) })
+	if p := writableDir(dir); p == nil {
+		t.Errorf("read-only %s accepted", dir)
+	}
+}
+
+func TestDoctorSpecs(t *testing.T) {
+	var doctor []string
+	for _, spec := range EnvVarSpecs() {
+		if spec.Doctor {
+			doctor = append(doctor, spec.Name)
+		}
+	}
+	want := []string{"HIVE_MCP_DIR", "BB_MCP_DIR", "OPENROUTER_API_KEY", "HIVE_OPENROUTER_URL"}
+	if len(doctor) != len(want) {
+		t.Fatalf("doctor specs = %v, want %v", doctor, want)
+	}
+	for i := range want {
+		if doctor[i] != want[i] {
+			t.Errorf("doctor specs = %v, want %v", doctor, want)
+		}
+	}
+}
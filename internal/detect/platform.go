@@ -1,7 +1,9 @@
 package detect
 
 import (
+	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -110,7 +112,7 @@ func detectPackageManagerByCommand() string {
 // ShellInfo contains information about the user's shell
 type ShellInfo struct {
 	Status     Status
-	Name       string // "bash", "zsh"
+	Name       string // "bash", "zsh", "fish", "nu", "sh"
 	ConfigFile string // path to config file
 	Version    string
 }
@@ -127,21 +129,23 @@ func DetectShell() ShellInfo {
 	// Get version
 	var versionCmd string
 	switch info.Name {
-	case "bash":
-		versionCmd = "bash"
-	case "zsh":
-		versionCmd = "zsh"
+	case "bash", "zsh", "fish", "nu":
+		versionCmd = info.Name
+	case "sh", "dash", "ash", "ksh", "mksh":
+		// POSIX shells read ~/.profile and have no portable --version
 	default:
 		info.Status = StatusWarning
 		return info
 	}
 
-	out, err := exec.Command(versionCmd, "--version").Output()
-	if err == nil {
-		// Extract first line
-		lines := strings.Split(string(out), "\n")
-		if len(lines) > 0 {
-			info.Version = strings.TrimSpace(lines[0])
+	if versionCmd != "" {
+		out, err := exec.Command(versionCmd, "--version").Output()
+		if err == nil {
+			// Extract first line
+			lines := strings.Split(string(out), "\n")
+			if len(lines) > 0 {
+				info.Version = strings.TrimSpace(lines[0])
+			}
 		}
 	}
 
@@ -157,6 +161,41 @@ func DetectShell() ShellInfo {
 	return info
 }
 
+// ZshDir returns the directory zsh reads its dotfiles from ($ZDOTDIR,
+// defaulting to the home directory)
+func ZshDir() string {
+	if dir := os.Getenv("ZDOTDIR"); dir != "" {
+		return dir
+	}
+	return getEnv("HOME", "")
+}
+
+// FishConfigDir returns fish's configuration directory
+func FishConfigDir() string {
+	return filepath.Join(xdgConfigHome(), "fish")
+}
+
+// NuConfigDir returns nushell's configuration directory, asking nu
+// itself when it is installed
+func NuConfigDir() string {
+	if out, err := exec.Command("nu", "-c", "$nu.env-path | path dirname").Output(); err == nil {
+		if dir := strings.TrimSpace(string(out)); dir != "" {
+			return dir
+		}
+	}
+	if runtime.GOOS == "darwin" && os.Getenv("XDG_CONFIG_HOME") == "" {
+		return filepath.Join(getEnv("HOME", ""), "Library", "Application Support", "nushell")
+	}
+	return filepath.Join(xdgConfigHome(), "nushell")
+}
+
+func xdgConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(getEnv("HOME", ""), ".config")
+}
+
 func detectShellConfigFile(shell string) string {
 	home := getEnv("HOME", "")
 	if home == "" {
@@ -173,9 +212,15 @@ func detectShellConfigFile(shell string) string {
 		}
 	case "zsh":
 		candidates = []string{
-			home + "/.zshrc",
-			home + "/.zprofile",
+			filepath.Join(ZshDir(), ".zshrc"),
+			filepath.Join(ZshDir(), ".zprofile"),
 		}
+	case "fish":
+		candidates = []string{filepath.Join(FishConfigDir(), "config.fish")}
+	case "nu":
+		candidates = []string{filepath.Join(NuConfigDir(), "env.nu")}
+	default:
+		candidates = []string{home + "/.profile"}
 	}
 
 	for _, f := range candidates {
@@ -3,8 +3,8 @@ package detect
 import (
 	"os/exec"
 	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
 )
 
 // PrereqCheck contains the result of a prerequisite check
@@ -86,6 +86,16 @@ func CheckAllPrereqs() []PrereqCheck {
 	return results
 }
 
+// CheckPrereq checks the prerequisite invoked as command
+func CheckPrereq(command string) (PrereqCheck, bool) {
+	for _, spec := range prereqs {
+		if spec.command == command {
+			return checkPrereq(spec), true
+		}
+	}
+	return PrereqCheck{}, false
+}
+
 func checkPrereq(spec prereqSpec) PrereqCheck {
 	check := PrereqCheck{
 		Name:     spec.name,
@@ -130,7 +140,7 @@ func checkPrereq(spec prereqSpec) PrereqCheck {
 	}
 
 	// Compare versions
-	if compareVersions(check.Version, spec.minVersion) >= 0 {
+	if util.CompareVersions(check.Version, spec.minVersion) >= 0 {
 		check.Status = StatusOK
 	} else {
 		check.Status = StatusWarning
@@ -138,62 +148,3 @@ func checkPrereq(spec prereqSpec) PrereqCheck {
 
 	return check
 }
-
-// compareVersions compares two version strings
-// Returns: -1 if a < b, 0 if a == b, 1 if a > b
-func compareVersions(a, b string) int {
-	aParts := parseVersion(a)
-	bParts := parseVersion(b)
-
-	maxLen := len(aParts)
-	if len(bParts) > maxLen {
-		maxLen = len(bParts)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		aVal := 0
-		bVal := 0
-		if i < len(aParts) {
-			aVal = aParts[i]
-		}
-		if i < len(bParts) {
-			bVal = bParts[i]
-		}
-
-		if aVal < bVal {
-			return -1
-		}
-		if aVal > bVal {
-			return 1
-		}
-	}
-
-	return 0
-}
-
-func parseVersion(v string) []int {
-	// Remove any prefix like "v"
-	v = strings.TrimPrefix(v, "v")
-
-	// Split by dots
-	parts := strings.Split(v, ".")
-	result := make([]int, 0, len(parts))
-
-	for _, p := range parts {
-		// Extract leading digits only
-		digits := ""
-		for _, c := range p {
-			if c >= '0' && c <= '9' {
-				digits += string(c)
-			} else {
-				break
-			}
-		}
-		if digits != "" {
-			n, _ := strconv.Atoi(digits)
-			result = append(result, n)
-		}
-	}
-
-	return result
-}
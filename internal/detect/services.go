@@ -4,9 +4,10 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
 )
 
 // ServiceCheck contains the result of a service check
@@ -42,23 +43,22 @@ func CheckAllServices() []ServiceCheck {
 
 func checkEmacsDaemon() ServiceCheck {
 	check := ServiceCheck{}
+	server := emacs.Server()
 
 	// Check if emacsclient can connect to the daemon
-	cmd := exec.Command("emacsclient", "--eval", "(emacs-pid)")
+	cmd := emacs.Client(server, "--eval", "(emacs-pid)")
 	out, err := cmd.CombinedOutput()
-	if err != nil {
+	pid := strings.TrimSpace(string(out))
+	if err != nil || pid == "" || pid == "nil" {
 		check.Status = StatusMissing
-		check.Message = "Emacs daemon not running"
+		check.Message = fmt.Sprintf("Emacs %s not running", emacs.DisplayName(server))
 		return check
 	}
 
-	pid := strings.TrimSpace(string(out))
-	if pid != "" && pid != "nil" {
-		check.Status = StatusOK
-		check.Endpoint = fmt.Sprintf("PID %s", pid)
-	} else {
-		check.Status = StatusMissing
-		check.Message = "Emacs daemon not running"
+	check.Status = StatusOK
+	check.Endpoint = fmt.Sprintf("PID %s", pid)
+	if server != "" {
+		check.Endpoint += ", " + emacs.DisplayName(server)
 	}
 
 	return check
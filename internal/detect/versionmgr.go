@@ -0,0 +1,281 @@
+package detect
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VersionManager describes a tool version manager (mise, asdf, sdkman)
+// and the prerequisite tools it provides
+type VersionManager struct {
+	Name  string
+	Path  string // binary, or install directory for sdkman
+	Tools []ManagedTool
+}
+
+// ManagedTool is a prerequisite provided through a version manager
+type ManagedTool struct {
+	Tool      string   // prerequisite command (java, clojure, bb)
+	Plugin    string   // the manager's name for it
+	Installed []string // versions installed through the manager
+	Active    string   // version active in the hive-mcp directory
+	Source    string   // file pinning the active version, or "global"
+}
+
+// Supports reports whether the manager can install tool
+func (m VersionManager) Supports(tool string) bool {
+	_, ok := managerPlugins[m.Name][tool]
+	return ok
+}
+
+// Plugin returns the manager's name for tool
+func (m VersionManager) Plugin(tool string) string {
+	return managerPlugins[m.Name][tool]
+}
+
+// Tool returns the managed entry for tool
+func (m VersionManager) Tool(tool string) (ManagedTool, bool) {
+	for _, t := range m.Tools {
+		if t.Tool == tool {
+			return t, true
+		}
+	}
+	return ManagedTool{}, false
+}
+
+// managerPlugins maps prerequisite commands to each manager's plugin
+// or candidate name
+var managerPlugins = map[string]map[string]string{
+	"mise":   {"java": "java", "clojure": "clojure", "bb": "babashka"},
+	"asdf":   {"java": "java", "clojure": "clojure", "bb": "babashka"},
+	"sdkman": {"java": "java"},
+}
+
+// managerFiles lists the per-directory version files each manager reads
+var managerFiles = map[string][]string{
+	"mise":   {".mise.toml", "mise.toml", ".tool-versions"},
+	"asdf":   {".tool-versions"},
+	"sdkman": {".sdkmanrc"},
+}
+
+// DetectVersionManagers finds installed version managers and reports
+// the Java, Clojure and Babashka versions they provide in dir
+func DetectVersionManagers(dir string) []VersionManager {
+	var managers []VersionManager
+	if path, err := exec.LookPath("mise"); err == nil {
+		managers = append(managers, detectMise(path, dir))
+	}
+	if path, err := exec.LookPath("asdf"); err == nil {
+		managers = append(managers, detectAsdf(path, dir))
+	}
+	if home := SdkmanDir(); home != "" {
+		managers = append(managers, detectSdkman(home, ProjectPins(dir, "sdkman")))
+	}
+
+	for i := range managers {
+		pins := ProjectPins(dir, managers[i].Name)
+		for j, t := range managers[i].Tools {
+			if pin, ok := pins[t.Plugin]; ok {
+				managers[i].Tools[j].Source = pin.File
+			} else if t.Active != "" {
+				managers[i].Tools[j].Source = "global"
+			}
+		}
+	}
+	return managers
+}
+
+// FindVersionManager returns the detected manager with the given name
+func FindVersionManager(managers []VersionManager, name string) (VersionManager, bool) {
+	for _, m := range managers {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return VersionManager{}, false
+}
+
+// Pin is a tool version pinned by a file in a project directory
+type Pin struct {
+	Version string
+	File    string
+}
+
+// ProjectPins returns the tool versions pinned for a manager by dir's
+// version files, keyed by the manager's tool names
+func ProjectPins(dir, manager string) map[string]Pin {
+	pins := make(map[string]Pin)
+	for _, name := range managerFiles[manager] {
+		for tool, version := range readVersionFile(filepath.Join(dir, name)) {
+			if _, ok := pins[tool]; !ok {
+				pins[tool] = Pin{Version: version, File: name}
+			}
+		}
+	}
+	return pins
+}
+
+// readVersionFile returns the tool versions in a .tool-versions,
+// mise.toml or .sdkmanrc file
+func readVersionFile(path string) map[string]string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	tools := make(map[string]string)
+	toml := strings.HasSuffix(path, ".toml")
+	inTools := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		switch {
+		case toml && strings.HasPrefix(line, "["):
+			inTools = line == "[tools]"
+		case toml:
+			if key, value, ok := strings.Cut(line, "="); ok && inTools {
+				tools[strings.Trim(strings.TrimSpace(key), `"`)] = strings.Trim(strings.TrimSpace(value), `"[] `)
+			}
+		case strings.Contains(line, "="):
+			// .sdkmanrc: candidate=version
+			key, value, _ := strings.Cut(line, "=")
+			tools[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		default:
+			// .tool-versions: plugin version [version...]
+			if fields := strings.Fields(line); len(fields) >= 2 {
+				tools[fields[0]] = fields[1]
+			}
+		}
+	}
+	return tools
+}
+
+// managedTools returns an entry for every tool the manager supports
+func managedTools(manager string) []ManagedTool {
+	var tools []ManagedTool
+	for _, tool := range []string{"java", "clojure", "bb"} {
+		if plugin, ok := managerPlugins[manager][tool]; ok {
+			tools = append(tools, ManagedTool{Tool: tool, Plugin: plugin})
+		}
+	}
+	return tools
+}
+
+// detectMise reads installed and active versions from `mise ls --json`
+func detectMise(path, dir string) VersionManager {
+	m := VersionManager{Name: "mise", Path: path, Tools: managedTools("mise")}
+
+	cmd := exec.Command(path, "ls", "--json")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		return m
+	}
+	var listing map[string][]struct {
+		Version   string `json:"version"`
+		Installed bool   `json:"installed"`
+		Active    bool   `json:"active"`
+	}
+	if err := json.Unmarshal(out, &listing); err != nil {
+		return m
+	}
+
+	for i, t := range m.Tools {
+		for _, v := range listing[t.Plugin] {
+			if v.Installed {
+				m.Tools[i].Installed = append(m.Tools[i].Installed, v.Version)
+			}
+			if v.Active {
+				m.Tools[i].Active = v.Version
+			}
+		}
+	}
+	return m
+}
+
+// detectAsdf reads installed versions from `asdf list` and the active
+// one from `asdf current`, run in dir so .tool-versions applies
+func detectAsdf(path, dir string) VersionManager {
+	m := VersionManager{Name: "asdf", Path: path, Tools: managedTools("asdf")}
+
+	for i, t := range m.Tools {
+		out, err := exec.Command(path, "list", t.Plugin).Output()
+		if err != nil {
+			// Plugin not added
+			continue
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			v := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+			if v != "" && !strings.HasPrefix(v, "No ") {
+				m.Tools[i].Installed = append(m.Tools[i].Installed, v)
+			}
+		}
+
+		cmd := exec.Command(path, "current", t.Plugin)
+		cmd.Dir = dir
+		out, err = cmd.Output()
+		if err != nil {
+			continue
+		}
+		// Older asdf prints "java 17.0.2 /path/.tool-versions"; newer
+		// versions print a table with a header row
+		for _, line := range strings.Split(string(out), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == t.Plugin && fields[1] != "______" {
+				m.Tools[i].Active = fields[1]
+			}
+		}
+	}
+	return m
+}
+
+// SdkmanDir returns the sdkman installation directory, or "" when
+// sdkman isn't installed
+func SdkmanDir() string {
+	dir := os.Getenv("SDKMAN_DIR")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".sdkman")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "bin", "sdkman-init.sh")); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// detectSdkman lists candidate directories; the active version is the
+// .sdkmanrc pin if present, otherwise the "current" symlink
+func detectSdkman(home string, pins map[string]Pin) VersionManager {
+	m := VersionManager{Name: "sdkman", Path: home, Tools: managedTools("sdkman")}
+
+	for i, t := range m.Tools {
+		dir := filepath.Join(home, "candidates", t.Plugin)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() && e.Name() != "current" {
+				m.Tools[i].Installed = append(m.Tools[i].Installed, e.Name())
+			}
+		}
+		sort.Strings(m.Tools[i].Installed)
+
+		if target, err := os.Readlink(filepath.Join(dir, "current")); err == nil {
+			m.Tools[i].Active = filepath.Base(target)
+		}
+		if pin, ok := pins[t.Plugin]; ok {
+			m.Tools[i].Active = pin.Version
+		}
+	}
+	return m
+}
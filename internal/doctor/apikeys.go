@@ -0,0 +1,194 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/secrets"
+)
+
+// DefaultOpenRouterURL is OpenRouter's API base
+const DefaultOpenRouterURL = "https://openrouter.ai/api/v1"
+
+// keyValidationTimeout bounds the whole validation request
+var keyValidationTimeout = 5 * time.Second
+
+// keyExpiryWarning is how early an expiring key is reported
+const keyExpiryWarning = 14 * 24 * time.Hour
+
+// openRouterKey is the body of GET /key
+type openRouterKey struct {
+	Data struct {
+		Usage          float64    `json:"usage"`
+		Limit          *float64   `json:"limit"`
+		LimitRemaining *float64   `json:"limit_remaining"`
+		IsFreeTier     bool       `json:"is_free_tier"`
+		ExpiresAt      *time.Time `json:"expires_at"`
+		RateLimit      *struct {
+			Requests int    `json:"requests"`
+			Interval string `json:"interval"`
+		} `json:"rate_limit"`
+	} `json:"data"`
+}
+
+// ValidationEnabled reports whether config.yaml turns on key validation
+func ValidationEnabled() bool {
+	cfg, err := config.Load()
+	return err == nil && cfg.OpenRouter.Validate
+}
+
+// CheckAPIKeys validates provider credentials against their API
+func CheckAPIKeys() []CheckResult {
+	return []CheckResult{checkOpenRouterKey()}
+}
+
+// openRouterURL returns the API base from HIVE_OPENROUTER_URL or the
+// config, so a local stub can stand in for OpenRouter
+func openRouterURL() string {
+	base := DefaultOpenRouterURL
+	if cfg, err := config.Load(); err == nil && cfg.OpenRouter.BaseURL != "" {
+		base = cfg.OpenRouter.BaseURL
+	}
+	return strings.TrimSuffix(getEnv("HIVE_OPENROUTER_URL", base), "/")
+}
+
+// lookupKey reads a key from the environment, then the secrets store
+func lookupKey(name string) (string, string) {
+	if value := os.Getenv(name); value != "" {
+		return value, "environment"
+	}
+	if value, ok := secrets.Lookup(name); ok {
+		return value, "hive secrets"
+	}
+	return "", ""
+}
+
+func checkOpenRouterKey() CheckResult {
+	result := CheckResult{
+		Name:    "OpenRouter Key",
+		FixHint: "Create a key at https://openrouter.ai/keys and store it: hive secrets set OPENROUTER_API_KEY",
+	}
+
+	key, source := lookupKey("OPENROUTER_API_KEY")
+	if key == "" {
+		result.Status = StatusWarning
+		result.Message = "no OPENROUTER_API_KEY to validate"
+		return result
+	}
+
+	base := openRouterURL()
+	endpoint, err := url.Parse(base)
+	if err != nil || (endpoint.Scheme != "https" && endpoint.Scheme != "http") || endpoint.Host == "" {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("invalid OpenRouter URL %q", base)
+		result.FixHint = "Set openrouter.base_url / HIVE_OPENROUTER_URL to a URL such as " + DefaultOpenRouterURL
+		return result
+	}
+	if endpoint.Scheme != "https" && !isLoopback(endpoint.Hostname()) {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("not sending the key to %s over plain HTTP", endpoint.Host)
+		result.FixHint = "Use an https:// URL for openrouter.base_url / HIVE_OPENROUTER_URL"
+		return result
+	}
+	endpoint = endpoint.JoinPath("key")
+
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = err.Error()
+		return result
+	}
+	req.Header.Set("Authorization", "Bearer "+key)
+
+	client := &http.Client{Timeout: keyValidationTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("could not reach %s", endpoint.Host)
+		if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+			result.Message = fmt.Sprintf("no answer from %s within %s", endpoint.Host, keyValidationTimeout)
+		}
+		result.FixHint = "Check your network, or set openrouter.base_url / HIVE_OPENROUTER_URL"
+		return result
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		result.Status = StatusError
+		result.Message = fmt.Sprintf("key from %s rejected (HTTP %d): revoked or mistyped", source, resp.StatusCode)
+		return result
+	case resp.StatusCode != http.StatusOK:
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("could not validate key (HTTP %d from %s)", resp.StatusCode, endpoint.Host)
+		return result
+	}
+
+	var info openRouterKey
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("unexpected response from %s", endpoint.Host)
+		return result
+	}
+	return describeKey(result, info, source)
+}
+
+// isLoopback reports whether host is localhost or a loopback address,
+// where a plain HTTP stub can't leak the key
+func isLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// describeKey turns the key details into a status and message
+func describeKey(result CheckResult, info openRouterKey, source string) CheckResult {
+	data := info.Data
+	result.Status = StatusOK
+	parts := []string{"valid (" + source + ")"}
+
+	switch {
+	case data.Limit != nil && data.LimitRemaining != nil:
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f credit left", *data.LimitRemaining, *data.Limit))
+		if *data.LimitRemaining <= 0 {
+			result.Status = StatusWarning
+			result.FixHint = "Raise the key's credit limit at https://openrouter.ai/keys"
+			parts = append(parts, "credit exhausted")
+		}
+	case data.IsFreeTier:
+		parts = append(parts, "free tier")
+	default:
+		parts = append(parts, fmt.Sprintf("$%.2f used, no limit", data.Usage))
+	}
+	if rl := data.RateLimit; rl != nil && rl.Requests > 0 {
+		parts = append(parts, fmt.Sprintf("%d requests/%s", rl.Requests, rl.Interval))
+	}
+
+	if data.ExpiresAt != nil {
+		left := time.Until(*data.ExpiresAt)
+		switch {
+		case left <= 0:
+			result.Status = StatusError
+			result.FixHint = "Create a new key at https://openrouter.ai/keys and store it: hive secrets set OPENROUTER_API_KEY"
+			parts = append(parts, "expired "+data.ExpiresAt.Local().Format("2006-01-02"))
+		case left < keyExpiryWarning:
+			result.Status = StatusWarning
+			result.FixHint = "Replace the key before it expires: hive secrets set OPENROUTER_API_KEY"
+			parts = append(parts, fmt.Sprintf("expires in %d day(s)", int(left.Hours()/24)+1))
+		default:
+			parts = append(parts, "expires "+data.ExpiresAt.Local().Format("2006-01-02"))
+		}
+	}
+
+	result.Message = strings.Join(parts, ", ")
+	return result
+}
@@ -0,0 +1,172 @@
+package doctor
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubOpenRouter points the check at a local server answering GET /key
+// with handler, and provides the key through the environment
+func stubOpenRouter(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("OPENROUTER_API_KEY", "sk-or-test")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/key" || r.Header.Get("Authorization") != "Bearer sk-or-test" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(srv.Close)
+	t.Setenv("HIVE_OPENROUTER_URL", srv.URL+"/api/v1/")
+}
+
+func keyBody(data string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data": %s}`, data)
+	}
+}
+
+func TestCheckOpenRouterKey(t *testing.T) {
+	expired := time.Now().Add(-48 * time.Hour).UTC().Format(time.RFC3339)
+	soon := time.Now().Add(72 * time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		status  Status
+		message string
+	}{
+		{
+			name:    "valid with credit",
+			handler: keyBody(`{"usage": 2.5, "limit": 10, "limit_remaining": 7.5, "rate_limit": {"requests": 20, "interval": "10s"}}`),
+			status:  StatusOK,
+			message: "valid (environment), $7.50 of $10.00 credit left, 20 requests/10s",
+		},
+		{
+			name:    "free tier",
+			handler: keyBody(`{"usage": 0, "is_free_tier": true}`),
+			status:  StatusOK,
+			message: "valid (environment), free tier",
+		},
+		{
+			name:    "credit exhausted",
+			handler: keyBody(`{"usage": 10, "limit": 10, "limit_remaining": 0}`),
+			status:  StatusWarning,
+			message: "credit exhausted",
+		},
+		{
+			name:    "expired",
+			handler: keyBody(`{"usage": 1, "expires_at": "` + expired + `"}`),
+			status:  StatusError,
+			message: "expired ",
+		},
+		{
+			name:    "expiring",
+			handler: keyBody(`{"usage": 1, "expires_at": "` + soon + `"}`),
+			status:  StatusWarning,
+			message: "expires in 3 day(s)",
+		},
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error": "invalid key"}`, http.StatusUnauthorized)
+			},
+			status:  StatusError,
+			message: "rejected (HTTP 401)",
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			status:  StatusWarning,
+			message: "could not validate key (HTTP 502",
+		},
+		{
+			name:    "malformed body",
+			handler: func(w http.ResponseWriter, r *http.Request) { fmt.Fprint(w, "<html>") },
+			status:  StatusWarning,
+			message: "unexpected response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stubOpenRouter(t, tt.handler)
+			result := checkOpenRouterKey()
+			if result.Status != tt.status || !strings.Contains(result.Message, tt.message) {
+				t.Errorf("got %v %q, want %v containing %q", result.Status, result.Message, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckOpenRouterKeyTimeout(t *testing.T) {
+	old := keyValidationTimeout
+	keyValidationTimeout = 50 * time.Millisecond
+	t.Cleanup(func() { keyValidationTimeout = old })
+
+	release := make(chan struct{})
+	stubOpenRouter(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+	defer close(release)
+
+	result := checkOpenRouterKey()
+	if result.Status != StatusWarning || !strings.Contains(result.Message, "no answer from") {
+		t.Errorf("got %v %q, want a timeout warning", result.Status, result.Message)
+	}
+}
+
+func TestCheckOpenRouterKeyURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		message string
+	}{
+		{"openrouter.ai/api/v1", "invalid OpenRouter URL"},
+		{"https://", "invalid OpenRouter URL"},
+		{"ftp://openrouter.ai/api/v1", "invalid OpenRouter URL"},
+		{"http://openrouter.ai/api/v1", "over plain HTTP"},
+		{"http://10.0.0.5:8080", "over plain HTTP"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+			t.Setenv("OPENROUTER_API_KEY", "sk-or-test")
+			t.Setenv("HIVE_OPENROUTER_URL", tt.url)
+
+			result := checkOpenRouterKey()
+			if result.Status != StatusWarning || !strings.Contains(result.Message, tt.message) {
+				t.Errorf("got %v %q, want a warning containing %q", result.Status, result.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	for host, want := range map[string]bool{
+		"localhost":     true,
+		"127.0.0.1":     true,
+		"::1":           true,
+		"openrouter.ai": false,
+		"10.0.0.5":      false,
+	} {
+		if got := isLoopback(host); got != want {
+			t.Errorf("isLoopback(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// CheckCheckout verifies the hive-mcp checkout matches the configured
+// repository and ref
+func CheckCheckout() []CheckResult {
+	return []CheckResult{checkCheckout()}
+}
+
+func checkCheckout() CheckResult {
+	result := CheckResult{Name: "hive-mcp checkout"}
+
+	dir := util.ExpandPath(getEnv("HIVE_MCP_DIR", setup.DefaultHiveMCPDir()))
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		result.Status = StatusError
+		result.Message = fmt.Sprintf("no checkout at %s", dir)
+		result.FixHint = "Run: hive setup"
+		return result
+	}
+
+	co, err := setup.ReadCheckout(dir)
+	if err != nil {
+		result.Status = StatusError
+		result.Message = err.Error()
+		return result
+	}
+	result.Details = fmt.Sprintf("%s (%s)", co.Remote, co.Commit)
+
+	cfg, err := config.Load()
+	if err != nil {
+		result.Status = StatusWarning
+		result.Message = err.Error()
+		return result
+	}
+	step := &setup.CloneStep{HiveMCPDir: dir, URL: cfg.Repo.URL, Ref: cfg.Repo.Ref}
+	if problem := step.Verify(); problem != "" {
+		result.Status = StatusWarning
+		result.Message = problem
+		result.FixHint = fmt.Sprintf("Check out the configured ref in %s, or update repo in %s", dir, config.Path())
+		return result
+	}
+
+	result.Status = StatusOK
+	result.Message = "on " + co.Ref
+	return result
+}
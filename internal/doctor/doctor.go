@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/hive-agi/hive-mcp-cli/internal/services"
 )
 
 // Status represents the outcome of a health check
@@ -102,8 +103,19 @@ func (r *DoctorResult) FixableChecks() []CheckResult {
 	return fixable
 }
 
+// Options selects optional checks
+type Options struct {
+	// ValidateKeys calls provider APIs to check that keys are accepted
+	ValidateKeys bool
+}
+
 // RunAll executes all health checks and returns the results
 func RunAll() (*DoctorResult, error) {
+	return RunAllWith(Options{})
+}
+
+// RunAllWith runs the health checks plus the optional ones in opts
+func RunAllWith(opts Options) (*DoctorResult, error) {
 	result := &DoctorResult{}
 
 	// Version checks
@@ -112,18 +124,40 @@ func RunAll() (*DoctorResult, error) {
 		Checks: CheckVersions(),
 	})
 
+	// Repository checkout
+	result.Categories = append(result.Categories, Category{
+		Name:   "hive-mcp Checkout",
+		Checks: CheckCheckout(),
+	})
+
 	// Environment variables
 	result.Categories = append(result.Categories, Category{
 		Name:   "Environment Variables",
 		Checks: CheckEnvVars(),
 	})
 
+	// Provider credentials (network calls, so opt-in)
+	if opts.ValidateKeys || ValidationEnabled() {
+		result.Categories = append(result.Categories, Category{
+			Name:   "API Credentials",
+			Checks: CheckAPIKeys(),
+		})
+	}
+
 	// Service health
 	result.Categories = append(result.Categories, Category{
 		Name:   "Service Health",
 		Checks: CheckServices(),
 	})
 
+	// systemd user units
+	if services.HasSystemd() {
+		result.Categories = append(result.Categories, Category{
+			Name:   "Service Units",
+			Checks: CheckUnits(),
+		})
+	}
+
 	// MCP registration
 	result.Categories = append(result.Categories, Category{
 		Name:   "MCP Configuration",
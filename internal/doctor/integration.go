@@ -100,7 +100,7 @@ func CheckIntegration() []CheckResult {
 func checkEmacsMCPConnection() CheckResult {
 	result := CheckResult{
 		Name:    "Emacs MCP Connection",
-		FixHint: "Ensure Emacs daemon is running and hive-mcp.el is loaded",
+		FixHint: "Ensure Emacs daemon is running and hive-mcp.el is loaded (hive setup adds it to your Emacs config)",
 	}
 
 	// Try to execute emacs_status via MCP
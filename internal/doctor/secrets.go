@@ -0,0 +1,115 @@
+package doctor
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+)
+
+var (
+	// Assignments in POSIX shells, fish and nushell
+	rcAssignments = []*regexp.Regexp{
+		regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`),
+		regexp.MustCompile(`^\s*set\s+(?:-[a-zA-Z]+\s+)*([A-Za-z_][A-Za-z0-9_]*)\s+(.*)$`),
+		regexp.MustCompile(`^\s*\$env\.([A-Za-z_][A-Za-z0-9_]*)\s*=\s*(.*)$`),
+	}
+
+	// secretName matches variable names that hold credentials
+	secretName = regexp.MustCompile(`(?i)(API_?KEY|TOKEN|SECRET|PASSWORD|PASSWD)`)
+)
+
+// rcFiles returns the shell startup files that exist
+func rcFiles() []string {
+	home, _ := os.UserHomeDir()
+	candidates := []string{
+		filepath.Join(home, ".bashrc"),
+		filepath.Join(home, ".bash_profile"),
+		filepath.Join(home, ".profile"),
+		filepath.Join(detect.ZshDir(), ".zshrc"),
+		filepath.Join(detect.ZshDir(), ".zshenv"),
+		filepath.Join(detect.ZshDir(), ".zprofile"),
+		filepath.Join(detect.FishConfigDir(), "config.fish"),
+		filepath.Join(detect.NuConfigDir(), "env.nu"),
+		filepath.Join(detect.NuConfigDir(), "config.nu"),
+	}
+	fishConfD, _ := filepath.Glob(filepath.Join(detect.FishConfigDir(), "conf.d", "*.fish"))
+	candidates = append(candidates, fishConfD...)
+
+	var files []string
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, path)
+		}
+	}
+	return files
+}
+
+// plaintextSecrets finds credentials assigned a literal value in path,
+// as "NAME (line N)". Values are never returned.
+func plaintextSecrets(path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+
+	var found []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		for _, re := range rcAssignments {
+			m := re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			if secretName.MatchString(m[1]) && isLiteral(m[2]) {
+				found = append(found, fmt.Sprintf("%s (line %d)", m[1], n))
+			}
+			break
+		}
+	}
+	return found
+}
+
+// isLiteral reports whether an assigned value is a literal rather
+// than read from a command or another variable
+func isLiteral(value string) bool {
+	value = strings.Trim(strings.TrimSpace(value), `"'`)
+	if value == "" {
+		return false
+	}
+	return !strings.ContainsAny(value[:1], "$(`")
+}
+
+// checkPlaintextSecrets warns about API keys and tokens written into
+// shell startup files
+func checkPlaintextSecrets() CheckResult {
+	result := CheckResult{
+		Name:    "Plaintext Secrets",
+		FixHint: "Move them with 'hive secrets set NAME', then delete the lines from your shell config",
+	}
+
+	var found []string
+	for _, path := range rcFiles() {
+		for _, secret := range plaintextSecrets(path) {
+			found = append(found, fmt.Sprintf("%s in %s", secret, shortPath(path)))
+		}
+	}
+
+	if len(found) > 0 {
+		result.Status = StatusWarning
+		result.Message = strings.Join(found, "; ")
+		return result
+	}
+	result.Status = StatusOK
+	result.Message = "no credentials in shell configs"
+	return result
+}
@@ -4,9 +4,12 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/services"
 )
 
 // CheckServices runs all service health checks
@@ -19,19 +22,23 @@ func CheckServices() []CheckResult {
 }
 
 func checkEmacsDaemon() CheckResult {
+	server := emacs.Server()
 	result := CheckResult{
 		Name:    "Emacs Daemon",
-		FixHint: "Start daemon: emacs --daemon",
+		FixHint: "Start daemon: emacs " + emacs.DaemonFlag(server),
 		CanFix:  true,
 		Fix:     startEmacsDaemon,
 	}
 
 	// Check if emacsclient can connect to the daemon
-	cmd := exec.Command("emacsclient", "--eval", "(emacs-pid)")
+	cmd := emacs.Client(server, "--eval", "(emacs-pid)")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
 		result.Status = StatusError
-		result.Message = "not running"
+		result.Message = fmt.Sprintf("%s not running", emacs.DisplayName(server))
+		if live := emacs.LiveSockets(); len(live) > 0 {
+			result.Details = "Other live Emacs servers: " + emacs.FormatSockets(live)
+		}
 		return result
 	}
 
@@ -48,14 +55,13 @@ func checkEmacsDaemon() CheckResult {
 }
 
 func startEmacsDaemon() error {
-	cmd := exec.Command("emacs", "--daemon")
-	return cmd.Run()
+	return services.Start(&services.Emacs{})
 }
 
 func checkChromaService() CheckResult {
 	result := CheckResult{
 		Name:    "Chroma (Vector DB)",
-		FixHint: "Start Chroma: docker run -d -p 8000:8000 chromadb/chroma",
+		FixHint: "Start Chroma: docker run -d -p 8000:8000 " + chroma.DefaultImage,
 		CanFix:  true,
 		Fix:     startChromaContainer,
 	}
@@ -90,24 +96,7 @@ func checkChromaService() CheckResult {
 }
 
 func startChromaContainer() error {
-	// Check if container already exists (stopped)
-	checkCmd := exec.Command("docker", "ps", "-a", "--filter", "name=chroma", "--format", "{{.Names}}")
-	out, err := checkCmd.Output()
-	if err == nil && strings.TrimSpace(string(out)) == "chroma" {
-		// Container exists, try to start it
-		startCmd := exec.Command("docker", "start", "chroma")
-		if err := startCmd.Run(); err == nil {
-			return nil
-		}
-	}
-
-	// Run new container
-	cmd := exec.Command("docker", "run", "-d",
-		"--name", "chroma",
-		"-p", "8000:8000",
-		"-v", "chroma-data:/chroma/chroma",
-		"chromadb/chroma")
-	return cmd.Run()
+	return services.Start(&services.Chroma{})
 }
 
 func checkOllamaService() CheckResult {
@@ -148,9 +137,54 @@ func checkOllamaService() CheckResult {
 }
 
 func startOllama() error {
-	// Start ollama serve in background
-	cmd := exec.Command("ollama", "serve")
-	return cmd.Start() // Don't wait, it's a daemon
+	return services.Start(&services.Ollama{})
+}
+
+// CheckUnits reports the state of the systemd user units written by
+// 'hive services install-units'
+func CheckUnits() []CheckResult {
+	var results []CheckResult
+	for _, name := range services.Names() {
+		results = append(results, checkUnit(services.UnitName(name)))
+	}
+	return results
+}
+
+func checkUnit(unit string) CheckResult {
+	result := CheckResult{
+		Name:    unit,
+		FixHint: "Install units: hive services install-units",
+	}
+
+	state := services.UnitState(unit)
+	if !state.Installed {
+		result.Status = StatusWarning
+		result.Message = "not installed (optional)"
+		result.Details = "Services started without a unit stop on logout and are not restarted"
+		return result
+	}
+
+	result.FixHint = "Enable unit: systemctl --user enable --now " + unit
+	result.CanFix = true
+	result.Fix = func() error { return services.EnableUnit(unit) }
+
+	switch {
+	case state.Active == "failed":
+		result.Status = StatusError
+		result.Message = "failed"
+		result.Details = "Inspect with: journalctl --user -u " + unit
+	case state.Active != "active":
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("%s, %s", state.Enabled, state.Active)
+	case state.Enabled != "enabled":
+		result.Status = StatusWarning
+		result.Message = fmt.Sprintf("active but %s (won't start at login)", state.Enabled)
+	default:
+		result.Status = StatusOK
+		result.Message = "enabled, active"
+	}
+
+	return result
 }
 
 // CheckObservability checks optional observability stack
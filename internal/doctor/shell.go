@@ -0,0 +1,84 @@
+package doctor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// shellBlockVars are the variables the managed shell block exports
+var shellBlockVars = []string{"HIVE_MCP_DIR", "BB_MCP_DIR"}
+
+// checkShellBlock compares the variables in the managed shell blocks
+// with the current environment and config.yaml's env section
+func checkShellBlock() CheckResult {
+	result := CheckResult{
+		Name:    "Shell Config",
+		FixHint: "Run 'hive setup' to rewrite the managed block, then restart your shell",
+	}
+
+	blocks := setup.ReadShellBlocks()
+	if len(blocks) == 0 {
+		result.Status = StatusWarning
+		result.Message = "no hive-mcp-cli managed block in any shell config"
+		return result
+	}
+
+	var cfgEnv map[string]string
+	if cfg, err := config.Load(); err == nil {
+		cfgEnv = cfg.Env
+	}
+
+	var drift []string
+	for _, name := range shellBlockVars {
+		var sources []string
+		values := make(map[string]bool)
+		for _, block := range blocks {
+			if block.Eval {
+				// Loaded from 'hive env', which reads the same config
+				continue
+			}
+			value := block.Vars[name]
+			sources = append(sources, fmt.Sprintf("%s=%s", shortPath(block.Path), orUnset(value)))
+			values[value] = true
+		}
+		sources = append(sources, "environment="+orUnset(os.Getenv(name)))
+		values[os.Getenv(name)] = true
+		if value, ok := cfgEnv[name]; ok {
+			sources = append(sources, "config="+orUnset(value))
+			values[value] = true
+		}
+
+		if len(values) > 1 {
+			drift = append(drift, fmt.Sprintf("%s differs (%s)", name, strings.Join(sources, ", ")))
+		}
+	}
+
+	if len(drift) > 0 {
+		result.Status = StatusWarning
+		result.Message = strings.Join(drift, "; ")
+		return result
+	}
+	result.Status = StatusOK
+	result.Message = fmt.Sprintf("%d shell config(s) match the environment", len(blocks))
+	return result
+}
+
+func orUnset(value string) string {
+	if value == "" {
+		return "unset"
+	}
+	return value
+}
+
+// shortPath abbreviates the home directory as ~
+func shortPath(path string) string {
+	home, _ := os.UserHomeDir()
+	if home != "" && strings.HasPrefix(path, home+string(os.PathSeparator)) {
+		return "~" + path[len(home):]
+	}
+	return path
+}
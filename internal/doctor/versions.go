@@ -5,8 +5,10 @@ import (
 	"os"
 	"os/exec"
 	"regexp"
-	"strconv"
-	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/secrets"
 )
 
 // getEnv returns the value of an environment variable or a default
@@ -141,7 +143,7 @@ func checkVersion(spec versionSpec) CheckResult {
 	}
 
 	// Compare versions
-	cmp := compareVersions(version, spec.minVersion)
+	cmp := util.CompareVersions(version, spec.minVersion)
 	if cmp >= 0 {
 		result.Status = StatusOK
 		result.Message = fmt.Sprintf("v%s (>= %s)", version, spec.minVersion)
@@ -154,109 +156,72 @@ func checkVersion(spec versionSpec) CheckResult {
 	return result
 }
 
-// compareVersions compares two version strings
-// Returns: -1 if a < b, 0 if a == b, 1 if a > b
-func compareVersions(a, b string) int {
-	aParts := parseVersion(a)
-	bParts := parseVersion(b)
-
-	maxLen := len(aParts)
-	if len(bParts) > maxLen {
-		maxLen = len(bParts)
-	}
-
-	for i := 0; i < maxLen; i++ {
-		aVal := 0
-		bVal := 0
-		if i < len(aParts) {
-			aVal = aParts[i]
-		}
-		if i < len(bParts) {
-			bVal = bParts[i]
-		}
-
-		if aVal < bVal {
-			return -1
-		}
-		if aVal > bVal {
-			return 1
-		}
-	}
-
-	return 0
-}
-
-func parseVersion(v string) []int {
-	// Remove any prefix like "v"
-	v = strings.TrimPrefix(v, "v")
-
-	// Split by dots
-	parts := strings.Split(v, ".")
-	result := make([]int, 0, len(parts))
-
-	for _, p := range parts {
-		// Extract leading digits only
-		digits := ""
-		for _, c := range p {
-			if c >= '0' && c <= '9' {
-				digits += string(c)
-			} else {
-				break
-			}
-		}
-		if digits != "" {
-			n, _ := strconv.Atoi(digits)
-			result = append(result, n)
-		}
-	}
-
-	return result
+// envFixHints are shown when a variable is unset
+var envFixHints = map[string]string{
+	"HIVE_MCP_DIR":       "Add to shell config: export HIVE_MCP_DIR=$HOME/hive-mcp",
+	"BB_MCP_DIR":         "Add to shell config: export BB_MCP_DIR=$HIVE_MCP_DIR",
+	"OPENROUTER_API_KEY": "Get API key from https://openrouter.ai and store it: hive secrets set OPENROUTER_API_KEY",
 }
 
-// CheckEnvVars checks required environment variables
+// CheckEnvVars checks that the environment variables hive-mcp needs
+// are set and hold usable values
 func CheckEnvVars() []CheckResult {
 	var results []CheckResult
 
-	envSpecs := []struct {
-		name     string
-		required bool
-		fixHint  string
-	}{
-		{"HIVE_MCP_DIR", true, "Add to shell config: export HIVE_MCP_DIR=$HOME/hive-mcp"},
-		{"BB_MCP_DIR", true, "Add to shell config: export BB_MCP_DIR=$HOME/bb-mcp"},
-		{"OPENROUTER_API_KEY", false, "Get API key from https://openrouter.ai and add to shell config"},
-	}
-
-	for _, spec := range envSpecs {
-		results = append(results, checkEnvVar(spec.name, spec.required, spec.fixHint))
+	for _, spec := range detect.EnvVarSpecs() {
+		if !spec.Doctor {
+			continue
+		}
+		if spec.IfSet && os.Getenv(spec.Name) == "" {
+			continue
+		}
+		results = append(results, checkEnvVar(spec))
 	}
+	results = append(results, checkShellBlock(), checkPlaintextSecrets())
 
 	return results
 }
 
-func checkEnvVar(name string, required bool, fixHint string) CheckResult {
+func checkEnvVar(spec detect.EnvVarSpec) CheckResult {
 	result := CheckResult{
-		Name:    name,
-		FixHint: fixHint,
+		Name:    spec.Name,
+		FixHint: envFixHints[spec.Name],
 	}
 
-	value := getEnv(name, "")
-	if value != "" {
-		// Mask sensitive values
-		displayVal := value
-		if strings.Contains(strings.ToLower(name), "key") || strings.Contains(strings.ToLower(name), "secret") {
-			if len(displayVal) > 8 {
-				displayVal = displayVal[:4] + "****" + displayVal[len(displayVal)-4:]
-			} else {
-				displayVal = "****"
+	check := detect.CheckEnvVar(spec)
+	source := ""
+	if check.Value == "" {
+		if value, ok := secrets.Lookup(spec.Name); ok {
+			// Injected into hive-mcp at launch rather than exported
+			check.Value, check.Problem = value, nil
+			if spec.Validate != nil {
+				check.Problem = spec.Validate(value)
 			}
+			source = "stored in hive secrets"
+		}
+	}
+
+	switch {
+	case check.Problem != nil:
+		result.Status = StatusError
+		if !spec.Required {
+			result.Status = StatusWarning
 		}
+		result.Message = check.Problem.Reason
+		result.FixHint = check.Problem.Fix
+	case source != "":
+		result.Status = StatusOK
+		result.Message = source
+	case check.Value != "":
 		result.Status = StatusOK
-		result.Message = displayVal
-	} else if required {
+		result.Message = check.Value
+		if spec.Sensitive {
+			result.Message = detect.MaskValue(check.Value)
+		}
+	case spec.Required:
 		result.Status = StatusError
 		result.Message = "not set (required)"
-	} else {
+	default:
 		result.Status = StatusWarning
 		result.Message = "not set (optional)"
 	}
@@ -0,0 +1,222 @@
+// Package download fetches pinned release artifacts and verifies them
+// against known SHA-256 checksums before setup executes or unpacks them
+package download
+
+import (
+	"bufio"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+//go:embed artifacts.txt
+var manifest string
+
+// Artifact is a pinned release file
+type Artifact struct {
+	Name    string // stable identifier, used for checksum overrides
+	Version string
+	URL     string
+	SHA256  string // hex digest, "" when none is built in
+}
+
+// FileName is the artifact's file name as published upstream
+func (a Artifact) FileName() string {
+	name := path.Base(a.URL)
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return name
+}
+
+// Artifacts returns the pinned artifacts built into the CLI
+func Artifacts() []Artifact {
+	var artifacts []Artifact
+	scanner := bufio.NewScanner(strings.NewReader(manifest))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			continue
+		}
+		a := Artifact{Name: fields[0], Version: fields[1], URL: fields[2]}
+		if fields[3] != "-" {
+			a.SHA256 = strings.ToLower(fields[3])
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts
+}
+
+// Lookup returns the pinned artifact with the given name
+func Lookup(name string) (Artifact, error) {
+	for _, a := range Artifacts() {
+		if a.Name == name {
+			return a, nil
+		}
+	}
+	return Artifact{}, fmt.Errorf("no pinned artifact named %q", name)
+}
+
+// ErrNoChecksum is returned for artifacts without a known checksum
+var ErrNoChecksum = errors.New("no checksum known")
+
+// ChecksumError reports an artifact whose contents don't match its
+// pinned checksum
+type ChecksumError struct {
+	Name string
+	Want string
+	Got  string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("checksum mismatch for %s: expected sha256 %s, got %s", e.Name, e.Want, e.Got)
+}
+
+// Fetcher downloads artifacts into a local cache
+type Fetcher struct {
+	CacheDir  string            // verified artifacts are kept here
+	Mirror    string            // optional local directory tried before the network
+	Checksums map[string]string // overrides for the built-in checksums
+}
+
+// NewFetcher returns a fetcher configured from config.yaml. The
+// HIVE_DOWNLOAD_MIRROR environment variable overrides the mirror.
+func NewFetcher() (*Fetcher, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	mirror := util.GetEnv("HIVE_DOWNLOAD_MIRROR", cfg.Downloads.Mirror)
+	return &Fetcher{
+		CacheDir:  filepath.Join(util.CacheDir(), "downloads"),
+		Mirror:    util.ExpandPath(mirror),
+		Checksums: cfg.Downloads.Checksums,
+	}, nil
+}
+
+// Fetch returns the local path of a verified artifact using the
+// configured fetcher
+func Fetch(name string) (string, error) {
+	f, err := NewFetcher()
+	if err != nil {
+		return "", err
+	}
+	return f.Fetch(name)
+}
+
+// checksum returns the expected digest for an artifact
+func (f *Fetcher) checksum(a Artifact) (string, error) {
+	if sum, ok := f.Checksums[a.Name]; ok && sum != "" {
+		return strings.ToLower(sum), nil
+	}
+	if a.SHA256 != "" {
+		return a.SHA256, nil
+	}
+	return "", fmt.Errorf("%w for %s %s; set downloads.checksums.%s in %s",
+		ErrNoChecksum, a.Name, a.Version, a.Name, config.Path())
+}
+
+// Fetch returns the local path of the named artifact, downloading it
+// if it isn't cached. The file is only returned once its SHA-256
+// matches the pinned checksum; mismatching downloads are deleted.
+func (f *Fetcher) Fetch(name string) (string, error) {
+	a, err := Lookup(name)
+	if err != nil {
+		return "", err
+	}
+	want, err := f.checksum(a)
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(f.CacheDir, a.Name, a.Version)
+	dest := filepath.Join(dir, a.FileName())
+	if got, err := hashFile(dest); err == nil && got == want {
+		return dest, nil
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".partial-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	h := sha256.New()
+	err = f.copySource(a, io.MultiWriter(tmp, h))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+
+	if got := hex.EncodeToString(h.Sum(nil)); got != want {
+		return "", &ChecksumError{Name: a.Name, Want: want, Got: got}
+	}
+	if err := os.Rename(tmp.Name(), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// copySource writes the artifact's contents to w, preferring the mirror
+func (f *Fetcher) copySource(a Artifact, w io.Writer) error {
+	if f.Mirror != "" {
+		src, err := os.Open(filepath.Join(f.Mirror, a.FileName()))
+		if err == nil {
+			defer src.Close()
+			_, err = io.Copy(w, src)
+			return err
+		}
+		if !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	client := &http.Client{Timeout: 10 * time.Minute}
+	resp, err := client.Get(a.URL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d", a.URL, resp.StatusCode)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// hashFile returns the hex SHA-256 of a file
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
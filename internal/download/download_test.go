@@ -0,0 +1,188 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixture writes contents to the mirror under the artifact's file name
+// and returns its digest
+func fixture(t *testing.T, mirror, name string, contents []byte) string {
+	t.Helper()
+	a, err := Lookup(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(mirror, a.FileName()), contents, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(contents)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestArtifactsParse(t *testing.T) {
+	artifacts := Artifacts()
+	if len(artifacts) == 0 {
+		t.Fatal("no pinned artifacts")
+	}
+	seen := make(map[string]bool)
+	for _, a := range artifacts {
+		if seen[a.Name] {
+			t.Errorf("duplicate artifact %s", a.Name)
+		}
+		seen[a.Name] = true
+		if a.Version == "" || a.URL == "" {
+			t.Errorf("%s: missing version or URL", a.Name)
+		}
+		if a.SHA256 != "" && len(a.SHA256) != 64 {
+			t.Errorf("%s: malformed sha256 %q", a.Name, a.SHA256)
+		}
+	}
+	for _, name := range []string{"clojure-tools", "babashka-linux-amd64", "jdk-17-linux-x64"} {
+		if !seen[name] {
+			t.Errorf("%s is not pinned", name)
+		}
+	}
+}
+
+// Every shipped artifact needs a built-in checksum; an unpinned one
+// fails every install that uses it
+func TestArtifactsPinned(t *testing.T) {
+	for _, line := range strings.Split(manifest, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 4 {
+			t.Errorf("malformed row %q", line)
+			continue
+		}
+		sum := fields[3]
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != 64 {
+			t.Errorf("%s: sha256 %q is not pinned; run scripts/pin-artifacts.sh", fields[0], sum)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	a, err := Lookup("jdk-17-linux-x64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := a.FileName(), "OpenJDK17U-jdk_x64_linux_hotspot_17.0.13_11.tar.gz"; got != want {
+		t.Errorf("FileName() = %q, want %q", got, want)
+	}
+}
+
+func TestFetchFromMirror(t *testing.T) {
+	mirror := t.TempDir()
+	sum := fixture(t, mirror, "clojure-tools", []byte("clojure tools fixture"))
+	f := &Fetcher{
+		CacheDir:  t.TempDir(),
+		Mirror:    mirror,
+		Checksums: map[string]string{"clojure-tools": sum},
+	}
+
+	path, err := f.Fetch("clojure-tools")
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "clojure tools fixture" {
+		t.Fatalf("cached file = %q, %v", data, err)
+	}
+
+	// A verified copy in the cache is used without the mirror
+	if err := os.RemoveAll(mirror); err != nil {
+		t.Fatal(err)
+	}
+	again, err := f.Fetch("clojure-tools")
+	if err != nil || again != path {
+		t.Fatalf("second Fetch = %q, %v; want cached %q", again, err, path)
+	}
+}
+
+func TestFetchChecksumMismatch(t *testing.T) {
+	mirror := t.TempDir()
+	fixture(t, mirror, "clojure-tools", []byte("tampered"))
+	cache := t.TempDir()
+	f := &Fetcher{
+		CacheDir:  cache,
+		Mirror:    mirror,
+		Checksums: map[string]string{"clojure-tools": "0000000000000000000000000000000000000000000000000000000000000000"},
+	}
+
+	path, err := f.Fetch("clojure-tools")
+	var mismatch *ChecksumError
+	if !errors.As(err, &mismatch) {
+		t.Fatalf("Fetch = %q, %v; want ChecksumError", path, err)
+	}
+	if mismatch.Name != "clojure-tools" {
+		t.Errorf("ChecksumError.Name = %q", mismatch.Name)
+	}
+
+	// Nothing that failed verification is left behind
+	err = filepath.Walk(cache, func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			t.Errorf("unverified file left in cache: %s", p)
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFetchReplacesCorruptCache(t *testing.T) {
+	mirror := t.TempDir()
+	sum := fixture(t, mirror, "clojure-tools", []byte("good"))
+	f := &Fetcher{
+		CacheDir:  t.TempDir(),
+		Mirror:    mirror,
+		Checksums: map[string]string{"clojure-tools": sum},
+	}
+	path, err := f.Fetch("clojure-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("corrupted"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := f.Fetch("clojure-tools"); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "good" {
+		t.Errorf("cache = %q, want the verified mirror copy", data)
+	}
+}
+
+func TestFetchWithoutChecksum(t *testing.T) {
+	a, err := Lookup("clojure-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.SHA256 != "" {
+		t.Skip("clojure-tools has a built-in checksum")
+	}
+
+	mirror := t.TempDir()
+	fixture(t, mirror, "clojure-tools", []byte("unpinned"))
+	f := &Fetcher{CacheDir: t.TempDir(), Mirror: mirror}
+	if _, err := f.Fetch("clojure-tools"); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("Fetch = %v, want ErrNoChecksum", err)
+	}
+}
+
+func TestFetchUnknownArtifact(t *testing.T) {
+	f := &Fetcher{CacheDir: t.TempDir(), Mirror: t.TempDir()}
+	if _, err := f.Fetch("no-such-artifact"); err == nil {
+		t.Fatal("Fetch of an unpinned name succeeded")
+	}
+}
@@ -0,0 +1,140 @@
+// Package emacs locates and talks to the Emacs server hosting hive-mcp
+package emacs
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+)
+
+// Server returns the configured Emacs server name or socket path.
+// HIVE_EMACS_SERVER overrides the config file; "" means the default
+// server.
+func Server() string {
+	if name := os.Getenv("HIVE_EMACS_SERVER"); name != "" {
+		return name
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return ""
+	}
+	return cfg.Emacs.ServerName
+}
+
+// DisplayName returns a human readable name for a server
+func DisplayName(server string) string {
+	if server == "" {
+		return "server"
+	}
+	return fmt.Sprintf("server %q", server)
+}
+
+// ClientArgs prefixes emacsclient arguments with the socket selection
+// for server
+func ClientArgs(server string, args ...string) []string {
+	if server == "" {
+		return args
+	}
+	return append([]string{"--socket-name=" + server}, args...)
+}
+
+// Client returns an emacsclient command targeting server
+func Client(server string, args ...string) *exec.Cmd {
+	return exec.Command("emacsclient", ClientArgs(server, args...)...)
+}
+
+// DaemonFlag returns the emacs flag that starts a daemon for server
+func DaemonFlag(server string) string {
+	if server == "" {
+		return "--daemon"
+	}
+	return "--daemon=" + server
+}
+
+// Socket describes an Emacs server socket found on disk
+type Socket struct {
+	Name string // server name (socket file name)
+	Path string
+	Live bool // accepts connections
+}
+
+// socketDirs returns the directories Emacs creates server sockets in
+func socketDirs() []string {
+	uid := fmt.Sprint(os.Getuid())
+
+	var dirs []string
+	if runDir := os.Getenv("XDG_RUNTIME_DIR"); runDir != "" {
+		dirs = append(dirs, filepath.Join(runDir, "emacs"))
+	}
+	if tmp := os.Getenv("TMPDIR"); tmp != "" && filepath.Clean(tmp) != "/tmp" {
+		dirs = append(dirs, filepath.Join(tmp, "emacs"+uid))
+	}
+	return append(dirs, filepath.Join("/tmp", "emacs"+uid))
+}
+
+// Sockets lists Emacs server sockets in the standard socket directories
+func Sockets() []Socket {
+	var sockets []Socket
+	seen := map[string]bool{}
+
+	for _, dir := range socketDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.Type()&os.ModeSocket == 0 {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			sockets = append(sockets, Socket{
+				Name: entry.Name(),
+				Path: path,
+				Live: socketLive(path),
+			})
+		}
+	}
+
+	sort.Slice(sockets, func(i, j int) bool { return sockets[i].Path < sockets[j].Path })
+	return sockets
+}
+
+// LiveSockets returns only sockets accepting connections
+func LiveSockets() []Socket {
+	var live []Socket
+	for _, s := range Sockets() {
+		if s.Live {
+			live = append(live, s)
+		}
+	}
+	return live
+}
+
+// FormatSockets renders sockets as "name (path), ..."
+func FormatSockets(sockets []Socket) string {
+	parts := make([]string, 0, len(sockets))
+	for _, s := range sockets {
+		parts = append(parts, fmt.Sprintf("%s (%s)", s.Name, s.Path))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func socketLive(path string) bool {
+	conn, err := net.DialTimeout("unix", path, 500*time.Millisecond)
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
@@ -0,0 +1,99 @@
+package hive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/bundle"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// bundleCmd groups offline bundle commands
+var bundleCmd = &bonzai.Cmd{
+	Name:  "bundle",
+	Alias: "bd",
+	Short: "build offline setup bundles",
+
+	Long: `Build a bundle for installing hive-mcp on machines without network
+access.
+
+Commands:
+  create  - Gather everything hive setup downloads into one archive
+
+Examples:
+  hive bundle create                        # hive-bundle-<os>-<arch>-<date>.tar.gz
+  hive bundle create --out hive.tar.gz
+  hive setup --bundle hive.tar.gz           # on the offline machine`,
+
+	Cmds: []*bonzai.Cmd{helpCmd, bundleCreateCmd},
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		return showHelp(x)
+	},
+}
+
+// bundleCreateCmd builds an offline bundle from this machine's install
+var bundleCreateCmd = &bonzai.Cmd{
+	Name:  "create",
+	Alias: "c|build",
+	Short: "gather setup downloads into an offline bundle",
+	Usage: "hive bundle create [--out file.tar.gz] [--model name]...",
+
+	Long: `Create packs the following into a single archive with a manifest of
+SHA-256 checksums:
+
+  - the hive-mcp repository (git bundle of $HIVE_MCP_DIR)
+  - the Maven and gitlibs dependencies resolved by clojure -P
+  - the pinned Clojure, Babashka and JDK release tarballs
+  - the Chroma image (docker save)
+  - Ollama model blobs (default nomic-embed-text)
+
+Run it on a connected machine with the same OS and architecture as the
+target, after hive setup has completed. Components that aren't
+available (no Docker, model not pulled) are skipped with a warning.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		opts := bundle.CreateOptions{
+			HiveMCPDir: util.ExpandPath(util.GetEnv("HIVE_MCP_DIR", setup.DefaultHiveMCPDir())),
+		}
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--out" || arg == "-o":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a file", arg)
+				}
+				i++
+				opts.Out = args[i]
+			case strings.HasPrefix(arg, "--out="):
+				opts.Out = strings.TrimPrefix(arg, "--out=")
+			case arg == "--model":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a model name", arg)
+				}
+				i++
+				opts.Models = append(opts.Models, args[i])
+			case strings.HasPrefix(arg, "--model="):
+				opts.Models = append(opts.Models, strings.TrimPrefix(arg, "--model="))
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+		if opts.Out == "" {
+			opts.Out = bundle.DefaultBundlePath()
+		}
+
+		fmt.Println("Creating offline bundle...")
+		m, err := bundle.Create(opts)
+		if err != nil {
+			return fmt.Errorf("bundle failed: %w", err)
+		}
+
+		fmt.Printf("✓ Bundle written to %s (hive-mcp %.12s, %d files)\n", opts.Out, m.HiveMCPCommit, len(m.Files))
+		if len(m.Skipped) > 0 {
+			fmt.Printf("  Not included: %s\n", strings.Join(m.Skipped, ", "))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,140 @@
+package hive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+)
+
+// chromaCmd groups Chroma data management commands
+var chromaCmd = &bonzai.Cmd{
+	Name:  "chroma",
+	Alias: "ch",
+	Short: "manage Chroma vector memory data",
+
+	Long: `Manage the Chroma data volume holding hive-mcp's vector memory.
+
+Commands:
+  backup   - Archive the chroma-data volume
+  restore  - Restore the chroma-data volume from an archive
+
+Examples:
+  hive chroma backup                          # chroma-backup-<timestamp>.tar.zst
+  hive chroma backup --out memory.tar.gz
+  hive chroma restore memory.tar.gz
+  hive chroma restore old.tar.zst --force     # ignore version mismatch`,
+
+	Cmds: []*bonzai.Cmd{helpCmd, chromaBackupCmd, chromaRestoreCmd},
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		return showHelp(x)
+	},
+}
+
+// chromaBackupCmd archives the Chroma data volume
+var chromaBackupCmd = &bonzai.Cmd{
+	Name:  "backup",
+	Alias: "b|save",
+	Short: "archive the Chroma data volume",
+	Usage: "hive chroma backup [--out file.tar.zst] [--volume name]",
+
+	Long: `Backup stops the Chroma container, archives the chroma-data volume
+through a helper container and starts Chroma again. The archive records
+the Chroma version so restore can detect incompatible data.
+
+The output compression follows the file extension: .tar.zst (requires
+zstd), .tar.gz or .tar.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		opts := chroma.BackupOptions{}
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--out" || arg == "-o":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a file", arg)
+				}
+				i++
+				opts.Out = args[i]
+			case strings.HasPrefix(arg, "--out="):
+				opts.Out = strings.TrimPrefix(arg, "--out=")
+			case arg == "--volume":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a name", arg)
+				}
+				i++
+				opts.Volume = args[i]
+			case strings.HasPrefix(arg, "--volume="):
+				opts.Volume = strings.TrimPrefix(arg, "--volume=")
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+		if opts.Out == "" {
+			opts.Out = chroma.DefaultBackupPath()
+		}
+
+		fmt.Println("Backing up Chroma data...")
+		meta, err := chroma.Backup(opts)
+		if err != nil {
+			return fmt.Errorf("backup failed: %w", err)
+		}
+
+		version := meta.ChromaVersion
+		if version == "" {
+			version = "unknown"
+		}
+		fmt.Printf("✓ Volume %s saved to %s (Chroma %s)\n", meta.Volume, opts.Out, version)
+		return nil
+	},
+}
+
+// chromaRestoreCmd restores the Chroma data volume from a backup
+var chromaRestoreCmd = &bonzai.Cmd{
+	Name:  "restore",
+	Alias: "r|load",
+	Short: "restore the Chroma data volume from a backup",
+	Usage: "hive chroma restore <file> [--force] [--volume name]",
+
+	Long: `Restore stops the Chroma container, replaces the contents of the
+chroma-data volume with the archive and starts Chroma again.
+
+Restoring data written by an incompatible Chroma version (a different
+major release, a different minor release before 1.0, or a newer
+release) is refused unless --force is given.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		opts := chroma.RestoreOptions{}
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--force" || arg == "-f":
+				opts.Force = true
+			case arg == "--volume":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a name", arg)
+				}
+				i++
+				opts.Volume = args[i]
+			case strings.HasPrefix(arg, "--volume="):
+				opts.Volume = strings.TrimPrefix(arg, "--volume=")
+			case strings.HasPrefix(arg, "-"):
+				return fmt.Errorf("unknown argument: %s", arg)
+			default:
+				opts.File = arg
+			}
+		}
+		if opts.File == "" {
+			return fmt.Errorf("usage: %s", x.Usage)
+		}
+
+		fmt.Printf("Restoring Chroma data from %s...\n", opts.File)
+		meta, err := chroma.Restore(opts)
+		if err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+
+		fmt.Printf("✓ Restored backup from %s\n", meta.CreatedAt.Local().Format("2006-01-02 15:04"))
+		return nil
+	},
+}
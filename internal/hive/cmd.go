@@ -2,9 +2,14 @@ package hive
 
 import (
 	"fmt"
-	"runtime"
+	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/fatih/color"
+	"github.com/hive-agi/hive-mcp-cli/internal/bundle"
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
 	"github.com/hive-agi/hive-mcp-cli/internal/detect"
 	"github.com/hive-agi/hive-mcp-cli/internal/doctor"
 	"github.com/hive-agi/hive-mcp-cli/internal/setup"
@@ -99,6 +104,14 @@ Commands:
   detect  - Detect system prerequisites and installed components
   setup   - Install and configure hive-mcp components
   doctor  - Diagnose and fix common issues
+  update  - Upgrade hive-mcp to the configured ref
+  uninstall - Remove hive-mcp from this machine
+  shell   - Restore shell config backups
+  env     - Print the hive environment for eval
+  secrets - Store API keys outside shell configs
+  services - Manage Emacs daemon, Chroma and Ollama
+  chroma  - Back up and restore Chroma data
+  bundle  - Build offline setup bundles
   help    - Display help information
 
 Examples:
@@ -107,7 +120,7 @@ Examples:
   hive doctor          # Diagnose issues
   hive help detect     # Show help for detect command`,
 
-	Cmds: []*bonzai.Cmd{helpCmd, detectCmd, setupCmd, doctorCmd},
+	Cmds: []*bonzai.Cmd{helpCmd, detectCmd, setupCmd, doctorCmd, updateCmd, uninstallCmd, shellCmd, envCmd, secretsCmd, servicesCmd, chromaCmd, bundleCmd},
 
 	// Show help when called without arguments
 	Do: func(x *bonzai.Cmd, args ...string) error {
@@ -178,29 +191,147 @@ var setupCmd = &bonzai.Cmd{
   3. Install prerequisites (platform-specific)
   4. Download Clojure dependencies
   5. Sync Emacs packages
-  6. Setup Docker volumes and Chroma
-  7. Configure Ollama with embedding model
-  8. Start Emacs daemon
-  9. Register MCP server with Claude CLI`,
+  6. Configure Emacs to load hive-mcp
+  7. Setup Docker volumes and Chroma
+  8. Configure Ollama with embedding model
+  9. Start Emacs daemon
+  10. Register MCP server with Claude CLI
+
+Options:
+  --user                      Install Clojure, Babashka and a JDK into
+                              ~/.local without sudo; tools that need a
+                              system install are listed for your admin
+  --emacs-timeout <duration>  How long to wait for the Emacs daemon to
+                              load hive-mcp (default 60s)
+  --version-manager <name>    Install Java, Clojure and Babashka through
+                              mise, asdf or sdkman ("auto" uses the first
+                              one detected)
+  --bundle <file>             Install from an offline bundle made with
+                              'hive bundle create', without network access
+  --repo <url>                Clone hive-mcp from this remote or local path
+                              (default repo.url in config.yaml, else GitHub)
+  --ref <ref>                 Check out this tag, branch or commit SHA
+  --depth <n>                 Make a shallow clone of n commits
+  --shell-mode <mode>         "literal" writes the variables into shell
+                              configs; "eval" writes one line that runs
+                              'hive env' (default shell.mode in config.yaml)`,
 
 	Do: func(x *bonzai.Cmd, args ...string) error {
+		// Parse flags
+		var opts setup.Options
+		bundleFile := ""
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+		clone := &setup.CloneStep{URL: cfg.Repo.URL, Ref: cfg.Repo.Ref, Depth: cfg.Repo.Depth}
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--repo" || arg == "--ref" || arg == "--depth":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a value", arg)
+				}
+				i++
+				if err := setCloneOption(clone, arg, args[i]); err != nil {
+					return err
+				}
+			case strings.HasPrefix(arg, "--repo=") || strings.HasPrefix(arg, "--ref=") || strings.HasPrefix(arg, "--depth="):
+				name, value, _ := strings.Cut(arg, "=")
+				if err := setCloneOption(clone, name, value); err != nil {
+					return err
+				}
+			case arg == "--user":
+				opts.UserMode = true
+			case arg == "--version-manager":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a manager name", arg)
+				}
+				i++
+				opts.VersionManager = args[i]
+			case strings.HasPrefix(arg, "--version-manager="):
+				opts.VersionManager = strings.TrimPrefix(arg, "--version-manager=")
+			case arg == "--bundle":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a file", arg)
+				}
+				i++
+				bundleFile = args[i]
+			case strings.HasPrefix(arg, "--bundle="):
+				bundleFile = strings.TrimPrefix(arg, "--bundle=")
+			case arg == "--shell-mode":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a mode", arg)
+				}
+				i++
+				opts.ShellMode = setup.ShellMode(args[i])
+			case strings.HasPrefix(arg, "--shell-mode="):
+				opts.ShellMode = setup.ShellMode(strings.TrimPrefix(arg, "--shell-mode="))
+			case arg == "--emacs-timeout":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a duration", arg)
+				}
+				i++
+				d, err := time.ParseDuration(args[i])
+				if err != nil {
+					return fmt.Errorf("invalid --emacs-timeout: %w", err)
+				}
+				opts.EmacsTimeout = d
+			case strings.HasPrefix(arg, "--emacs-timeout="):
+				d, err := time.ParseDuration(strings.TrimPrefix(arg, "--emacs-timeout="))
+				if err != nil {
+					return fmt.Errorf("invalid --emacs-timeout: %w", err)
+				}
+				opts.EmacsTimeout = d
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+
+		if err := opts.Validate(cfg); err != nil {
+			return err
+		}
+
 		fmt.Println("🐝 hive-mcp setup")
 		fmt.Println()
 
-		// Determine platform
-		platform := runtime.GOOS
-
 		// Build step list
-		steps := []setup.Step{
-			&setup.CloneStep{},
-			&setup.ShellStep{},
-			&setup.PrerequisitesStep{Platform: platform},
-			&setup.CloneDepsStep{},
-			&setup.DoomSyncStep{},
-			&setup.ChromaStep{},
-			&setup.OllamaStep{},
-			&setup.EmacsDaemonStep{},
-			&setup.MCPStep{},
+		plan := setup.NewSteps(cfg, opts)
+		plan.Clone = clone
+		var importStep setup.Step
+
+		if bundleFile != "" {
+			dir, err := os.MkdirTemp("", "hive-bundle-")
+			if err != nil {
+				return err
+			}
+			defer os.RemoveAll(dir)
+
+			fmt.Printf("Unpacking bundle %s...\n", bundleFile)
+			manifest, err := bundle.Extract(bundleFile, dir)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("  hive-mcp %.12s, created %s\n\n", manifest.HiveMCPCommit, manifest.CreatedAt.Local().Format("2006-01-02"))
+
+			// Installer artifacts are served from the bundle but still
+			// verified against the checksums built into the CLI or set
+			// in config.yaml, never the bundle's own manifest
+			os.Setenv("HIVE_DOWNLOAD_MIRROR", bundle.DownloadsDir(dir))
+			plan.Clone.Source = bundle.RepoBundle(dir)
+			plan.Prereqs.Offline = true
+			plan.Ollama.Offline = true
+			if os.Geteuid() != 0 {
+				plan.Shell.ExtraPath = []string{"$HOME/.local/bin"}
+			}
+			importStep = &bundle.ImportStep{Dir: dir, Manifest: manifest}
+		}
+
+		var steps []setup.Step
+		for _, named := range plan.List() {
+			steps = append(steps, named.Step)
+			if named.ID == "prerequisites" && importStep != nil {
+				steps = append(steps, importStep)
+			}
 		}
 
 		// Create runner with progress output
@@ -210,6 +341,7 @@ var setupCmd = &bonzai.Cmd{
 		if err := runner.RunAll(); err != nil {
 			fmt.Println()
 			fmt.Printf("Setup failed: %v\n", err)
+			printAdminItems(plan.Prereqs.AdminItems)
 			fmt.Println("Run 'hive doctor' to diagnose issues.")
 			return err
 		}
@@ -218,8 +350,9 @@ var setupCmd = &bonzai.Cmd{
 		fmt.Println()
 		fmt.Println("Setup complete!")
 		fmt.Println()
+		printAdminItems(plan.Prereqs.AdminItems)
 		fmt.Println("Next steps:")
-		fmt.Println("  1. Restart your shell or run: source ~/.bashrc")
+		fmt.Println("  1. Restart your shell to pick up HIVE_MCP_DIR")
 		fmt.Println("  2. Verify with: hive doctor")
 		fmt.Println("  3. Start using: claude")
 		fmt.Println()
@@ -228,6 +361,35 @@ var setupCmd = &bonzai.Cmd{
 	},
 }
 
+// setCloneOption applies a --repo, --ref or --depth flag
+func setCloneOption(clone *setup.CloneStep, name, value string) error {
+	switch name {
+	case "--repo":
+		clone.URL = value
+	case "--ref":
+		clone.Ref = value
+	case "--depth":
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return fmt.Errorf("invalid --depth: %s", value)
+		}
+		clone.Depth = n
+	}
+	return nil
+}
+
+// printAdminItems lists tools a --user setup couldn't install itself
+func printAdminItems(items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Println("Some tools need a system install. Ask your administrator for:")
+	for _, item := range items {
+		fmt.Printf("  - %s\n", item)
+	}
+	fmt.Println()
+}
+
 // doctorCmd diagnoses and fixes issues
 var doctorCmd = &bonzai.Cmd{
 	Name:  "doctor",
@@ -250,22 +412,29 @@ var doctorCmd = &bonzai.Cmd{
   - Integration test (Emacs MCP connection)
   - Optional observability stack check
 
-Use --fix to attempt automatic fixes for fixable issues.`,
+Use --fix to attempt automatic fixes for fixable issues.
+
+Use --validate-keys to check OPENROUTER_API_KEY against the OpenRouter
+API (openrouter.base_url or HIVE_OPENROUTER_URL selects another
+endpoint). Set openrouter.validate in config.yaml to always do so.`,
 
 	Do: func(x *bonzai.Cmd, args ...string) error {
 		fmt.Println("Running hive-mcp health checks...")
 
-		// Check for --fix flag
+		// Check for --fix and --validate-keys flags
 		fix := false
+		var opts doctor.Options
 		for _, arg := range args {
-			if arg == "--fix" || arg == "-f" {
+			switch arg {
+			case "--fix", "-f":
 				fix = true
-				break
+			case "--validate-keys":
+				opts.ValidateKeys = true
 			}
 		}
 
 		// Run all health checks
-		result, err := doctor.RunAll()
+		result, err := doctor.RunAllWith(opts)
 		if err != nil {
 			return fmt.Errorf("health check failed: %w", err)
 		}
@@ -285,7 +454,7 @@ Use --fix to attempt automatic fixes for fixable issues.`,
 				// Re-run checks to show updated status
 				if fixed > 0 {
 					fmt.Println("\nRe-running health checks...")
-					result, _ = doctor.RunAll()
+					result, _ = doctor.RunAllWith(opts)
 					doctor.PrintResult(result)
 				}
 			} else {
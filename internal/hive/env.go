@@ -0,0 +1,109 @@
+package hive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/secrets"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// envCmd prints the variables setup would write to shell configs
+var envCmd = &bonzai.Cmd{
+	Name:  "env",
+	Short: "print the hive environment for eval",
+	Usage: "hive env [--shell bash|zsh|sh|fish|nu|json] [--path dir]",
+
+	Long: `Prints the exports the managed shell block would contain:
+HIVE_MCP_DIR, BB_MCP_DIR, extra PATH entries and the env section of
+config.yaml (HIVE_CONFIG selects another config file), plus the
+secrets stored with 'hive secrets'. Load it from your shell config
+instead of letting setup write values there:
+
+  bash/zsh:  eval "$(hive env --shell bash)"
+  fish:      hive env --shell fish | source
+  nushell:   hive env --shell json | from json | load-env
+
+'hive setup --shell-mode eval' writes these lines for you.
+
+Options:
+  --shell <name>  Output format (default: the shell in $SHELL)
+  --path <dir>    Prepend dir to PATH; may be repeated`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		shell := defaultEnvShell()
+		var extraPath []string
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--shell" || arg == "--path":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a value", arg)
+				}
+				i++
+				if arg == "--shell" {
+					shell = args[i]
+				} else {
+					extraPath = append(extraPath, args[i])
+				}
+			case strings.HasPrefix(arg, "--shell="):
+				shell = strings.TrimPrefix(arg, "--shell=")
+			case strings.HasPrefix(arg, "--path="):
+				extraPath = append(extraPath, strings.TrimPrefix(arg, "--path="))
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+
+		cfg, err := config.Load()
+		if err != nil {
+			return err
+		}
+
+		// Secrets are only ever printed here, never written to a file.
+		// Shell startup can't answer a prompt, so a passphrase-protected
+		// store is only read with HIVE_SECRETS_PASSPHRASE set.
+		env := make(map[string]string, len(cfg.Env))
+		for name, value := range cfg.Env {
+			env[name] = value
+		}
+		if secrets.Unattended() {
+			stored, err := loadSecrets()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "hive env: secrets not loaded: %v\n", err)
+			}
+			for name, value := range stored {
+				env[name] = value
+			}
+		}
+
+		step := &setup.ShellStep{ExtraPath: extraPath, Env: env}
+		script, err := step.Script(shell)
+		if err != nil {
+			return err
+		}
+		fmt.Print(script)
+		return nil
+	},
+}
+
+func loadSecrets() (map[string]string, error) {
+	store, err := secrets.OpenDefault()
+	if err != nil {
+		return nil, err
+	}
+	return secrets.Env(store)
+}
+
+// defaultEnvShell maps $SHELL to an env output format
+func defaultEnvShell() string {
+	name := filepath.Base(os.Getenv("SHELL"))
+	if slices.Contains(setup.EnvShells, name) {
+		return name
+	}
+	return "sh"
+}
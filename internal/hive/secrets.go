@@ -0,0 +1,228 @@
+package hive
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/secrets"
+	"github.com/hive-agi/hive-mcp-cli/internal/ui"
+)
+
+// secretsCmd manages credentials injected into hive-mcp at launch
+var secretsCmd = &bonzai.Cmd{
+	Name:  "secrets",
+	Alias: "secret",
+	Short: "store API keys outside shell configs",
+
+	Long: `Stores credentials such as OPENROUTER_API_KEY so they never need to
+be exported from ~/.bashrc. Secrets are named after the environment
+variable they become: 'hive env' and the registered MCP server
+inject them when hive-mcp is launched.
+
+By default secrets live in an age-encrypted file under
+~/.local/state/hive, keyed by ~/.config/hive/secrets.key (created on
+first use). config.yaml can switch to a passphrase or to pass or the
+desktop Secret Service keyring:
+
+  secrets:
+    backend: file            # file, pass or secret-service
+    passphrase: true         # file backend: passphrase instead of key
+
+Commands:
+  set   - Store a secret (value read from the terminal or stdin)
+  get   - Print a secret
+  list  - List stored secret names
+  rm    - Delete a secret
+  exec  - Run a command with the secrets in its environment
+
+Examples:
+  hive secrets set OPENROUTER_API_KEY
+  pass show openrouter | hive secrets set OPENROUTER_API_KEY
+  hive secrets exec -- bb --prn -m bb.hive-mcp.server/-main`,
+
+	Cmds: []*bonzai.Cmd{helpCmd, secretsSetCmd, secretsGetCmd, secretsListCmd, secretsRmCmd, secretsExecCmd},
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		return showHelp(x)
+	},
+}
+
+var secretsSetCmd = &bonzai.Cmd{
+	Name:  "set",
+	Short: "store a secret",
+	Usage: "hive secrets set NAME",
+
+	Long: `Reads the value without echoing it when run in a terminal, or from
+stdin otherwise. Values are never taken from arguments, where they
+would end up in shell history.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		name, err := secretName(args)
+		if err != nil {
+			return err
+		}
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			return err
+		}
+
+		var value string
+		if ui.IsTerminal() {
+			value, err = ui.PasswordPrompt("Value for " + name)
+		} else {
+			var data []byte
+			data, err = io.ReadAll(os.Stdin)
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+		if err != nil {
+			return err
+		}
+		if value == "" {
+			return errors.New("empty value, nothing stored")
+		}
+
+		if err := store.Set(name, value); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Stored %s in %s\n", name, store.Name())
+		return nil
+	},
+}
+
+var secretsGetCmd = &bonzai.Cmd{
+	Name:  "get",
+	Short: "print a secret",
+	Usage: "hive secrets get NAME",
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		name, err := secretName(args)
+		if err != nil {
+			return err
+		}
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			return err
+		}
+		value, err := store.Get(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("%s is not stored in %s", name, store.Name())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+		return nil
+	},
+}
+
+var secretsListCmd = &bonzai.Cmd{
+	Name:  "list",
+	Alias: "ls",
+	Short: "list stored secret names",
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			return err
+		}
+		names, err := store.List()
+		if err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			fmt.Printf("No secrets in %s\n", store.Name())
+			return nil
+		}
+		for _, name := range names {
+			fmt.Println(name)
+		}
+		return nil
+	},
+}
+
+var secretsRmCmd = &bonzai.Cmd{
+	Name:  "rm",
+	Alias: "delete",
+	Short: "delete a secret",
+	Usage: "hive secrets rm NAME",
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		name, err := secretName(args)
+		if err != nil {
+			return err
+		}
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			return err
+		}
+		err = store.Delete(name)
+		if errors.Is(err, secrets.ErrNotFound) {
+			return fmt.Errorf("%s is not stored in %s", name, store.Name())
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✓ Removed %s from %s\n", name, store.Name())
+		return nil
+	},
+}
+
+var secretsExecCmd = &bonzai.Cmd{
+	Name:  "exec",
+	Short: "run a command with secrets in its environment",
+	Usage: "hive secrets exec -- COMMAND [ARGS...]",
+
+	Long: `Replaces itself with COMMAND after adding every stored secret to
+the environment. Setup registers the MCP server through this, so
+Claude launches hive-mcp with its keys without them being written to
+any config file.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		if len(args) > 0 && args[0] == "--" {
+			args = args[1:]
+		}
+		if len(args) == 0 {
+			return errors.New("usage: hive secrets exec -- COMMAND [ARGS...]")
+		}
+
+		store, err := secrets.OpenDefault()
+		if err != nil {
+			return err
+		}
+		env, err := secrets.Env(store)
+		if err != nil {
+			return err
+		}
+
+		bin, err := exec.LookPath(args[0])
+		if err != nil {
+			return err
+		}
+		// Stored secrets replace inherited values
+		var environ []string
+		for _, kv := range os.Environ() {
+			name, _, _ := strings.Cut(kv, "=")
+			if _, ok := env[name]; !ok {
+				environ = append(environ, kv)
+			}
+		}
+		for name, value := range env {
+			environ = append(environ, name+"="+value)
+		}
+		return syscall.Exec(bin, args, environ)
+	},
+}
+
+// secretName validates the single NAME argument
+func secretName(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", errors.New("expected one secret name, e.g. OPENROUTER_API_KEY")
+	}
+	return args[0], secrets.ValidateName(args[0])
+}
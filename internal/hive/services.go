@@ -0,0 +1,224 @@
+package hive
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/fatih/color"
+	"github.com/hive-agi/hive-mcp-cli/internal/services"
+)
+
+// servicesCmd manages the Emacs daemon, Chroma and Ollama
+var servicesCmd = &bonzai.Cmd{
+	Name:  "services",
+	Alias: "svc",
+	Short: "manage Emacs daemon, Chroma and Ollama",
+	Usage: "hive services <status|start|stop|restart|logs|install-units> [service] [--follow] [-n lines]",
+
+	// MCP metadata for AI tool discovery
+	Mcp: &bonzai.McpMeta{
+		Desc: "Manage hive-mcp background services (Emacs daemon, Chroma vector DB, Ollama). Report status, or start, stop, restart a service and wait until it is healthy, or show its recent logs.",
+		Params: []bonzai.McpParam{
+			{Name: "action", Desc: "Lifecycle action to perform", Type: "string", Required: true,
+				Enum: []string{"status", "start", "stop", "restart", "logs"}},
+			{Name: "service", Desc: "Service to act on (all services when omitted, except for logs)", Type: "string",
+				Enum: []string{"emacs", "chroma", "ollama"}},
+		},
+	},
+
+	Long: `Services controls the background services hive-mcp depends on:
+  emacs   - Emacs daemon hosting hive-mcp.el
+  chroma  - Chroma vector database (Docker)
+  ollama  - Ollama embedding server
+
+Actions:
+  status   - Show whether services are running
+  start    - Start services and wait until they are healthy
+  stop     - Stop services
+  restart  - Stop and start services, waiting until they are healthy
+  logs     - Show a service's logs (--follow to stream, -n for line count)
+  install-units
+           - Write systemd --user units and enable them, so services
+             survive logout and reboot (--dir DIR only renders the
+             units into DIR, --no-enable skips enabling)
+
+Actions other than logs apply to all services when none is given.
+Services with an installed unit are controlled through systemctl.
+
+Examples:
+  hive services status
+  hive services restart chroma
+  hive services logs emacs --follow
+  hive services install-units`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		var action, name, unitDir string
+		follow, enable := false, true
+		lines := 100
+
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--follow" || arg == "-f":
+				follow = true
+			case arg == "-n" || arg == "--lines":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a number", arg)
+				}
+				i++
+				n, err := strconv.Atoi(args[i])
+				if err != nil {
+					return fmt.Errorf("invalid line count: %s", args[i])
+				}
+				lines = n
+			case arg == "--dir":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a directory", arg)
+				}
+				i++
+				unitDir = args[i]
+			case strings.HasPrefix(arg, "--dir="):
+				unitDir = strings.TrimPrefix(arg, "--dir=")
+			case arg == "--no-enable":
+				enable = false
+			case strings.HasPrefix(arg, "-"):
+				return fmt.Errorf("unknown argument: %s", arg)
+			case action == "":
+				action = arg
+			case name == "":
+				name = arg
+			default:
+				return fmt.Errorf("unexpected argument: %s", arg)
+			}
+		}
+
+		if action == "" {
+			action = "status"
+		}
+
+		targets := services.All()
+		if name != "" {
+			svc, err := services.Get(name)
+			if err != nil {
+				return err
+			}
+			targets = []services.Service{svc}
+		}
+
+		switch action {
+		case "status":
+			for _, svc := range targets {
+				printServiceStatus(svc.Status())
+			}
+			return nil
+		case "start", "stop", "restart":
+			return runServiceAction(action, targets)
+		case "logs":
+			if name == "" {
+				return fmt.Errorf("logs requires a service (%s)", strings.Join(services.Names(), ", "))
+			}
+			return services.Logs(targets[0], follow, lines)
+		case "install-units":
+			return installUnits(targets, unitDir, enable)
+		default:
+			return fmt.Errorf("unknown action %q\nUsage: %s", action, x.Usage)
+		}
+	},
+}
+
+// runServiceAction applies a lifecycle action to each service,
+// continuing past failures so one dead service doesn't block the rest
+func runServiceAction(action string, targets []services.Service) error {
+	verbs := map[string]string{"start": "Starting", "stop": "Stopping", "restart": "Restarting"}
+
+	var failed []string
+	for _, svc := range targets {
+		fmt.Printf("→ %s %s...\n", verbs[action], svc.Name())
+
+		var err error
+		switch action {
+		case "start":
+			err = services.Start(svc)
+		case "stop":
+			err = services.Stop(svc)
+		case "restart":
+			err = services.Restart(svc)
+		}
+
+		if err != nil {
+			fmt.Printf("  %s %s: %v\n", color.RedString("✗"), svc.Name(), err)
+			failed = append(failed, svc.Name())
+			continue
+		}
+		printServiceStatus(svc.Status())
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%s failed for: %s", action, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// installUnits renders systemd user units for the targeted services.
+// With dir set the units are only written there, which needs no
+// systemd at all.
+func installUnits(targets []services.Service, dir string, enable bool) error {
+	opts, err := services.DefaultUnitOptions()
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	for _, svc := range targets {
+		wanted[svc.Name()] = true
+	}
+	var units []services.Unit
+	for _, unit := range services.RenderUnits(opts) {
+		if wanted[unit.Service] {
+			units = append(units, unit)
+		}
+	}
+	if len(units) == 0 {
+		return fmt.Errorf("no units to install - emacs, docker and ollama were not found on PATH")
+	}
+
+	if dir == "" {
+		dir = services.UnitDir()
+	} else {
+		enable = false
+	}
+	if err := services.WriteUnits(dir, units); err != nil {
+		return err
+	}
+	for _, unit := range units {
+		fmt.Printf("  %s wrote %s\n", color.GreenString("✓"), filepath.Join(dir, unit.Name))
+	}
+
+	if !enable {
+		return nil
+	}
+	if !services.HasSystemd() {
+		return fmt.Errorf("systemd user manager not available - units written but not enabled")
+	}
+	fmt.Println("Enabling units...")
+	return services.EnableUnits(units)
+}
+
+func printServiceStatus(status services.Status) {
+	if status.Running {
+		fmt.Printf("  %s %s: running", color.GreenString("✓"), status.Service)
+		if status.Endpoint != "" {
+			fmt.Printf(" (%s)", status.Endpoint)
+		}
+		fmt.Println()
+		return
+	}
+
+	fmt.Printf("  %s %s: stopped", color.RedString("✗"), status.Service)
+	if status.Message != "" {
+		fmt.Printf(" - %s", status.Message)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,97 @@
+package hive
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// shellCmd groups commands for the config files setup edits
+var shellCmd = &bonzai.Cmd{
+	Name:  "shell",
+	Alias: "sh",
+	Short: "manage shell config edits",
+
+	Long: `Setup edits shell and Emacs config files only through a marked
+block. Symlinks (stow, chezmoi, ...) are followed, files are replaced
+atomically with their mode kept, and the previous contents are saved
+under ~/.local/state/hive/backups before every change.
+
+Commands:
+  restore  - List backups or restore one
+
+Examples:
+  hive shell restore       # list backups, newest first
+  hive shell restore 2     # restore the second backup in the list`,
+
+	Cmds: []*bonzai.Cmd{helpCmd, shellRestoreCmd},
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		return showHelp(x)
+	},
+}
+
+// shellRestoreCmd rolls a config file back to a backup
+var shellRestoreCmd = &bonzai.Cmd{
+	Name:  "restore",
+	Alias: "r",
+	Short: "restore a config file from a backup",
+	Usage: "hive shell restore [number|backup-file]",
+
+	Long: `Without arguments, restore lists the saved backups, newest first.
+Given a number from that list (or a backup file name), it writes the
+backup back to the file it was taken from. The current contents are
+backed up first, so a restore can be undone the same way.`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		if len(args) > 1 {
+			return fmt.Errorf("unexpected argument: %s", args[1])
+		}
+		backups, err := setup.Backups()
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 0 {
+			if len(backups) == 0 {
+				fmt.Printf("No backups in %s\n", setup.BackupDir())
+				return nil
+			}
+			fmt.Println("Backups (newest first):")
+			for i, b := range backups {
+				fmt.Printf("  %2d  %s  %s\n", i+1, b.Time.Format("2006-01-02 15:04:05"), b.Path)
+			}
+			fmt.Println("\nRun 'hive shell restore <number>' to restore one.")
+			return nil
+		}
+
+		backup, err := findBackup(backups, args[0])
+		if err != nil {
+			return err
+		}
+		if err := setup.RestoreBackup(backup); err != nil {
+			return fmt.Errorf("restore failed: %w", err)
+		}
+		fmt.Printf("✓ Restored %s from %s\n", backup.Path, backup.Time.Format("2006-01-02 15:04:05"))
+		return nil
+	},
+}
+
+// findBackup selects a backup by list number or file name
+func findBackup(backups []setup.Backup, arg string) (setup.Backup, error) {
+	if n, err := strconv.Atoi(arg); err == nil {
+		if n < 1 || n > len(backups) {
+			return setup.Backup{}, fmt.Errorf("no backup number %d (%d available)", n, len(backups))
+		}
+		return backups[n-1], nil
+	}
+	for _, b := range backups {
+		if b.File == arg || filepath.Base(b.File) == filepath.Base(arg) {
+			return b, nil
+		}
+	}
+	return setup.Backup{}, fmt.Errorf("no backup named %s", arg)
+}
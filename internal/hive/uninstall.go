@@ -0,0 +1,96 @@
+package hive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/ui"
+	"github.com/hive-agi/hive-mcp-cli/internal/uninstall"
+)
+
+// uninstallCmd removes what setup installed
+var uninstallCmd = &bonzai.Cmd{
+	Name:  "uninstall",
+	Alias: "remove",
+	Short: "remove hive-mcp from this machine",
+	Usage: "hive uninstall [--only a,b] [--purge-data] [--remove-checkout] [-y]",
+
+	Long: `Uninstall reverses setup, removing in order:
+  mcp          - the 'emacs' MCP server registration in Claude CLI
+  shell        - the managed block in shell config files
+  emacs-config - the managed block in the Emacs config
+  units        - the hive-* systemd user units
+  emacs        - the running Emacs daemon
+  chroma       - the Chroma container (its data volume is kept)
+  data         - the Chroma data volume, only with --purge-data
+  checkout     - the hive-mcp checkout, only with --remove-checkout
+
+Only components that are actually installed are listed. A summary is
+shown and confirmed before anything is removed.
+
+Options:
+  --only <a,b>         Remove only these components, including data
+                       or checkout when named
+  --purge-data         Also delete the Chroma data volume
+  --remove-checkout    Also delete the hive-mcp checkout
+  --yes, -y            Don't ask for confirmation
+  --dry-run, -n        Only show what would be removed`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		opts := uninstall.Options{}
+		yes, dryRun := false, false
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--purge-data":
+				opts.PurgeData = true
+			case arg == "--remove-checkout":
+				opts.RemoveCheckout = true
+			case arg == "--yes" || arg == "-y":
+				yes = true
+			case arg == "--dry-run" || arg == "-n":
+				dryRun = true
+			case arg == "--only":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a component list", arg)
+				}
+				i++
+				opts.Only = strings.Split(args[i], ",")
+			case strings.HasPrefix(arg, "--only="):
+				opts.Only = strings.Split(strings.TrimPrefix(arg, "--only="), ",")
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+
+		plan, err := uninstall.Plan(opts)
+		if err != nil {
+			return err
+		}
+		if len(plan) == 0 {
+			fmt.Println("Nothing to remove.")
+			return nil
+		}
+
+		fmt.Println("The following will be removed:")
+		for _, c := range plan {
+			fmt.Printf("  %-13s %s\n", c.Name, c.Summary)
+		}
+		fmt.Println()
+
+		if dryRun {
+			fmt.Println("Dry run: no changes made.")
+			return nil
+		}
+		if !yes && !ui.ConfirmPrompt("Proceed?") {
+			fmt.Println("Aborted.")
+			return nil
+		}
+
+		if err := uninstall.Remove(plan); err != nil {
+			return err
+		}
+		fmt.Println("\n✓ hive-mcp uninstalled")
+		return nil
+	},
+}
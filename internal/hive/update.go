@@ -0,0 +1,76 @@
+package hive
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/BuddhiLW/bonzai"
+	"github.com/hive-agi/hive-mcp-cli/internal/update"
+)
+
+// updateCmd upgrades an existing installation
+var updateCmd = &bonzai.Cmd{
+	Name:  "update",
+	Alias: "up|upgrade",
+	Short: "upgrade hive-mcp to the configured ref",
+	Usage: "hive update [--ref ref] [--stash] [--dry-run]",
+
+	Long: `Update fetches the hive-mcp checkout's origin, shows the incoming
+commits and moves the checkout to the configured ref (repo.ref in
+config.yaml, or the current branch's upstream). Branches are only
+fast-forwarded.
+
+Only the affected setup steps are re-run:
+  deps.edn changed  - clojure -P
+  *.el changed      - doom sync (when Doom is installed)
+  bb.edn changed    - MCP server re-registration
+
+Health checks run before and after the update. If new failures appear,
+the checkout is rolled back to the previous commit.
+
+Options:
+  --ref <ref>   Update to this tag, branch or SHA instead of repo.ref
+  --stash       Stash local changes for the update and re-apply them
+  --dry-run     Only show incoming commits and affected steps`,
+
+	Do: func(x *bonzai.Cmd, args ...string) error {
+		opts := update.Options{}
+		for i := 0; i < len(args); i++ {
+			switch arg := args[i]; {
+			case arg == "--stash":
+				opts.Stash = true
+			case arg == "--dry-run" || arg == "-n":
+				opts.DryRun = true
+			case arg == "--ref":
+				if i+1 >= len(args) {
+					return fmt.Errorf("%s requires a ref", arg)
+				}
+				i++
+				opts.Ref = args[i]
+			case strings.HasPrefix(arg, "--ref="):
+				opts.Ref = strings.TrimPrefix(arg, "--ref=")
+			default:
+				return fmt.Errorf("unknown argument: %s", arg)
+			}
+		}
+
+		plan, err := update.Update(opts)
+		if errors.Is(err, update.ErrDirty) {
+			return err
+		}
+		if err != nil {
+			return fmt.Errorf("update failed: %w", err)
+		}
+
+		switch {
+		case plan.UpToDate():
+			fmt.Printf("✓ Already up to date (%.12s)\n", plan.To)
+		case opts.DryRun:
+			fmt.Println("Dry run: no changes made.")
+		default:
+			fmt.Printf("✓ Updated to %.12s\n", plan.To)
+		}
+		return nil
+	},
+}
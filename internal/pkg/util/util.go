@@ -1,7 +1,12 @@
 // Package util provides shared utilities for hive-mcp-cli
 package util
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
 
 // ExpandPath expands ~ to home directory
 func ExpandPath(path string) string {
@@ -46,3 +51,82 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// ParseVersion extracts the numeric components of a version string
+// ("v1.2.3-beta" -> [1 2 3])
+func ParseVersion(v string) []int {
+	v = strings.TrimPrefix(v, "v")
+
+	parts := strings.Split(v, ".")
+	result := make([]int, 0, len(parts))
+
+	for _, p := range parts {
+		// Extract leading digits only
+		digits := ""
+		for _, c := range p {
+			if c >= '0' && c <= '9' {
+				digits += string(c)
+			} else {
+				break
+			}
+		}
+		if digits != "" {
+			n, _ := strconv.Atoi(digits)
+			result = append(result, n)
+		}
+	}
+
+	return result
+}
+
+// CompareVersions compares two version strings
+// Returns: -1 if a < b, 0 if a == b, 1 if a > b
+func CompareVersions(a, b string) int {
+	aParts := ParseVersion(a)
+	bParts := ParseVersion(b)
+
+	maxLen := len(aParts)
+	if len(bParts) > maxLen {
+		maxLen = len(bParts)
+	}
+
+	for i := 0; i < maxLen; i++ {
+		aVal := 0
+		bVal := 0
+		if i < len(aParts) {
+			aVal = aParts[i]
+		}
+		if i < len(bParts) {
+			bVal = bParts[i]
+		}
+
+		if aVal < bVal {
+			return -1
+		}
+		if aVal > bVal {
+			return 1
+		}
+	}
+
+	return 0
+}
+
+// StateDir returns the directory for hive's persistent state
+// ($XDG_STATE_HOME/hive, defaulting to ~/.local/state/hive)
+func StateDir() string {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "hive")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "state", "hive")
+}
+
+// CacheDir returns the directory for hive's download cache
+// ($XDG_CACHE_HOME/hive, defaulting to ~/.cache/hive)
+func CacheDir() string {
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "hive")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".cache", "hive")
+}
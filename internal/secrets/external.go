@@ -0,0 +1,166 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// passPrefix is the folder hive's entries live in within the password
+// store
+const passPrefix = "hive"
+
+// PassStore keeps secrets in pass (passwordstore.org) as hive/<NAME>
+type PassStore struct{}
+
+func (PassStore) Name() string {
+	return "pass"
+}
+
+func (PassStore) Get(name string) (string, error) {
+	out, err := runTool(nil, "pass", "show", passPrefix+"/"+name)
+	if err != nil {
+		if strings.Contains(err.Error(), "is not in the password store") {
+			return "", ErrNotFound
+		}
+		return "", err
+	}
+	// pass entries keep the password on the first line
+	first, _, _ := strings.Cut(out, "\n")
+	return first, nil
+}
+
+func (PassStore) Set(name, value string) error {
+	_, err := runTool(strings.NewReader(value+"\n"), "pass", "insert", "--multiline", "--force", passPrefix+"/"+name)
+	return err
+}
+
+func (s PassStore) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	_, err := runTool(nil, "pass", "rm", "--force", passPrefix+"/"+name)
+	return err
+}
+
+func (PassStore) List() ([]string, error) {
+	dir := os.Getenv("PASSWORD_STORE_DIR")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".password-store")
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, passPrefix))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".gpg"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// secretServiceApp is the "service" attribute hive's items are stored
+// under
+const secretServiceApp = "hive-mcp-cli"
+
+// SecretServiceStore keeps secrets in the desktop keyring (GNOME
+// Keyring, KWallet) through libsecret's secret-tool
+type SecretServiceStore struct{}
+
+func (SecretServiceStore) Name() string {
+	return "Secret Service"
+}
+
+func (SecretServiceStore) Get(name string) (string, error) {
+	out, stderr, err := execTool(nil, "secret-tool", "lookup", "service", secretServiceApp, "account", name)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && out == "" && stderr == "" {
+		// secret-tool exits 1 without output for missing items; a
+		// locked keyring or missing D-Bus session says why on stderr
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return out, nil
+}
+
+func (SecretServiceStore) Set(name, value string) error {
+	_, err := runTool(strings.NewReader(value), "secret-tool", "store",
+		"--label", "hive "+name, "service", secretServiceApp, "account", name)
+	return err
+}
+
+func (s SecretServiceStore) Delete(name string) error {
+	if _, err := s.Get(name); err != nil {
+		return err
+	}
+	_, err := runTool(nil, "secret-tool", "clear", "service", secretServiceApp, "account", name)
+	return err
+}
+
+func (SecretServiceStore) List() ([]string, error) {
+	out, err := runTool(nil, "secret-tool", "search", "--all", "service", secretServiceApp)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// No matching items
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	scanner := bufio.NewScanner(strings.NewReader(out))
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "attribute.account = "); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// runTool runs a backend command, returning its stdout without the
+// trailing newline. stderr is included in the error.
+func runTool(stdin *strings.Reader, name string, args ...string) (string, error) {
+	out, _, err := execTool(stdin, name, args...)
+	return out, err
+}
+
+// execTool is runTool that also returns stderr, trimmed, and stdout on
+// failure, so callers can tell a quiet exit from a reported error
+func execTool(stdin *strings.Reader, name string, args ...string) (string, string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", "", fmt.Errorf("%s not found - install it or choose another secrets.backend", name)
+	}
+	cmd := exec.Command(name, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	out := strings.TrimSuffix(stdout.String(), "\n")
+	msg := strings.TrimSpace(stderr.String())
+	if err != nil {
+		if msg != "" {
+			return out, msg, fmt.Errorf("%s %s: %s: %w", name, args[0], msg, err)
+		}
+		return out, msg, fmt.Errorf("%s %s: %w", name, args[0], err)
+	}
+	return out, msg, nil
+}
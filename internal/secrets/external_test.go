@@ -0,0 +1,133 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretServiceGet(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		want     string
+		notFound bool
+		fails    bool
+	}{
+		{"found", "echo sk-123", "sk-123", false, false},
+		{"missing", "exit 1", "", true, true},
+		{"locked keyring", "echo 'Cannot unlock keyring' >&2; exit 1", "", false, true},
+		{"other exit", "exit 2", "", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			script := "#!/bin/sh\n" + tt.script + "\n"
+			if err := os.WriteFile(filepath.Join(dir, "secret-tool"), []byte(script), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", dir)
+
+			got, err := SecretServiceStore{}.Get("OPENROUTER_API_KEY")
+			if (err != nil) != tt.fails || errors.Is(err, ErrNotFound) != tt.notFound {
+				t.Fatalf("Get = %v; want failure %v, not found %v", err, tt.fails, tt.notFound)
+			}
+			if got != tt.want {
+				t.Errorf("Get = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// stubPass puts a pass stand-in on PATH that keeps entries unencrypted
+// under PASSWORD_STORE_DIR
+func stubPass(t *testing.T) string {
+	t.Helper()
+	bin := t.TempDir()
+	store := t.TempDir()
+	script := `#!/bin/sh
+dir="$PASSWORD_STORE_DIR"
+case "$1" in
+show)
+  [ -f "$dir/$2.gpg" ] || { echo "Error: $2 is not in the password store." >&2; exit 1; }
+  cat "$dir/$2.gpg" ;;
+insert)
+  mkdir -p "$(dirname "$dir/$4")"
+  cat > "$dir/$4.gpg" ;;
+rm)
+  rm "$dir/$3.gpg" ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(bin, "pass"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("PASSWORD_STORE_DIR", store)
+	return store
+}
+
+func TestPassStore(t *testing.T) {
+	store := stubPass(t)
+	var s PassStore
+
+	if _, err := s.Get("OPENROUTER_API_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on an empty store = %v, want ErrNotFound", err)
+	}
+	if names, err := s.List(); err != nil || len(names) != 0 {
+		t.Fatalf("List on an empty store = %v, %v", names, err)
+	}
+
+	if err := s.Set("OPENROUTER_API_KEY", "sk-or-1"); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := s.Set("GITHUB_TOKEN", "ghp_2"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(store, "hive", "OPENROUTER_API_KEY.gpg")); string(data) != "sk-or-1\n" {
+		t.Errorf("entry = %q, want it under hive/", data)
+	}
+	if value, err := s.Get("OPENROUTER_API_KEY"); err != nil || value != "sk-or-1" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+	if names, err := s.List(); err != nil || strings.Join(names, ",") != "GITHUB_TOKEN,OPENROUTER_API_KEY" {
+		t.Errorf("List = %v, %v", names, err)
+	}
+
+	if err := s.Delete("GITHUB_TOKEN"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := s.Delete("GITHUB_TOKEN"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+// Only the first line of a pass entry is the secret
+func TestPassStoreMultiline(t *testing.T) {
+	store := stubPass(t)
+	if err := os.MkdirAll(filepath.Join(store, "hive"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	entry := "sk-or-1\nurl: https://openrouter.ai\n"
+	if err := os.WriteFile(filepath.Join(store, "hive", "OPENROUTER_API_KEY.gpg"), []byte(entry), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := (PassStore{}).Get("OPENROUTER_API_KEY"); err != nil || value != "sk-or-1" {
+		t.Errorf("Get = %q, %v", value, err)
+	}
+}
+
+func TestPassStoreFailure(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'gpg: decryption failed: No secret key' >&2\nexit 2\n"
+	if err := os.WriteFile(filepath.Join(dir, "pass"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+
+	_, err := PassStore{}.Get("OPENROUTER_API_KEY")
+	if err == nil || errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "No secret key") {
+		t.Errorf("Get = %v, want the gpg error", err)
+	}
+}
@@ -0,0 +1,248 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// FilePath is the age-encrypted secrets file used by the file backend
+func FilePath() string {
+	return filepath.Join(util.StateDir(), "secrets.age")
+}
+
+// DefaultKeyFile is the age identity created for the file backend when
+// no key file or passphrase is configured
+func DefaultKeyFile() string {
+	return filepath.Join(config.Dir(), "secrets.key")
+}
+
+func keyFile(cfg config.Secrets) string {
+	if path := os.Getenv("HIVE_SECRETS_KEY_FILE"); path != "" {
+		return util.ExpandPath(path)
+	}
+	if cfg.KeyFile != "" {
+		return util.ExpandPath(cfg.KeyFile)
+	}
+	return DefaultKeyFile()
+}
+
+// FileStore keeps secrets as a JSON object in a file encrypted with age,
+// so the file can also be read with 'age -d -i <key file>'
+type FileStore struct {
+	Path    string
+	KeyFile string // age identity, created on first write
+
+	// Passphrase, when set, encrypts with an age scrypt passphrase
+	// instead of KeyFile
+	Passphrase func() (string, error)
+
+	passphrase string
+}
+
+func (s *FileStore) Name() string {
+	return "encrypted file " + s.Path
+}
+
+func (s *FileStore) Get(name string) (string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return "", err
+	}
+	value, ok := secrets[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return value, nil
+}
+
+func (s *FileStore) Set(name, value string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	secrets[name] = value
+	return s.save(secrets)
+}
+
+func (s *FileStore) Delete(name string) error {
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[name]; !ok {
+		return ErrNotFound
+	}
+	delete(secrets, name)
+	return s.save(secrets)
+}
+
+func (s *FileStore) List() ([]string, error) {
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return sortedKeys(secrets), nil
+}
+
+// load decrypts the store; a missing file is an empty store
+func (s *FileStore) load() (map[string]string, error) {
+	secrets := make(map[string]string)
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return secrets, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	identity, err := s.identity(false)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(f, identity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", s.Path, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt %s: %w", s.Path, err)
+	}
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.Path, err)
+	}
+	return secrets, nil
+}
+
+// save encrypts secrets and replaces the store atomically
+func (s *FileStore) save(secrets map[string]string) error {
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	recipient, err := s.recipient()
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, recipient)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0700); err != nil {
+		return err
+	}
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.Path)
+}
+
+// identity returns the decryption identity, generating the key file
+// when create is set and it doesn't exist yet
+func (s *FileStore) identity(create bool) (age.Identity, error) {
+	if s.Passphrase != nil {
+		pass, err := s.getPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return age.NewScryptIdentity(pass)
+	}
+
+	f, err := os.Open(s.KeyFile)
+	if os.IsNotExist(err) && create {
+		return s.generateKey()
+	}
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("key file %s not found; it is needed to decrypt %s", s.KeyFile, s.Path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	identities, err := age.ParseIdentities(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file %s: %w", s.KeyFile, err)
+	}
+	for _, id := range identities {
+		if x, ok := id.(*age.X25519Identity); ok {
+			return x, nil
+		}
+	}
+	return nil, fmt.Errorf("no X25519 identity in %s", s.KeyFile)
+}
+
+func (s *FileStore) recipient() (age.Recipient, error) {
+	if s.Passphrase != nil {
+		pass, err := s.getPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return age.NewScryptRecipient(pass)
+	}
+	identity, err := s.identity(true)
+	if err != nil {
+		return nil, err
+	}
+	x, ok := identity.(*age.X25519Identity)
+	if !ok {
+		return nil, errors.New("key file identity can't encrypt")
+	}
+	return x.Recipient(), nil
+}
+
+// getPassphrase asks once per process
+func (s *FileStore) getPassphrase() (string, error) {
+	if s.passphrase == "" {
+		pass, err := s.Passphrase()
+		if err != nil {
+			return "", err
+		}
+		if pass == "" {
+			return "", errors.New("empty passphrase")
+		}
+		s.passphrase = pass
+	}
+	return s.passphrase, nil
+}
+
+// generateKey writes a new age identity to KeyFile, readable only by
+// the user
+func (s *FileStore) generateKey() (age.Identity, error) {
+	identity, err := age.GenerateX25519Identity()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.KeyFile), 0700); err != nil {
+		return nil, err
+	}
+	content := fmt.Sprintf("# hive secrets key, public key: %s\n%s\n", identity.Recipient(), identity)
+	f, err := os.OpenFile(s.KeyFile, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create key file %s: %w", s.KeyFile, err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(content); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
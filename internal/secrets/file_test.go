@@ -0,0 +1,285 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+)
+
+func newFileStore(t *testing.T) *FileStore {
+	t.Helper()
+	dir := t.TempDir()
+	return &FileStore{
+		Path:    filepath.Join(dir, "state", "secrets.age"),
+		KeyFile: filepath.Join(dir, "config", "secrets.key"),
+	}
+}
+
+func TestFileStoreRoundTrip(t *testing.T) {
+	s := newFileStore(t)
+
+	if names, err := s.List(); err != nil || len(names) != 0 {
+		t.Fatalf("List on a new store = %v, %v", names, err)
+	}
+	if _, err := s.Get("OPENROUTER_API_KEY"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get on a new store = %v, want ErrNotFound", err)
+	}
+
+	for name, value := range map[string]string{"OPENROUTER_API_KEY": "sk-or-1", "GITHUB_TOKEN": "ghp_2"} {
+		if err := s.Set(name, value); err != nil {
+			t.Fatalf("Set(%s): %v", name, err)
+		}
+	}
+	if err := s.Set("OPENROUTER_API_KEY", "sk-or-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second store reads what the first wrote
+	again := &FileStore{Path: s.Path, KeyFile: s.KeyFile}
+	if value, err := again.Get("OPENROUTER_API_KEY"); err != nil || value != "sk-or-3" {
+		t.Errorf("Get = %q, %v; want the replaced value", value, err)
+	}
+	if names, err := again.List(); err != nil || !slices.Equal(names, []string{"GITHUB_TOKEN", "OPENROUTER_API_KEY"}) {
+		t.Errorf("List = %v, %v", names, err)
+	}
+
+	if err := again.Delete("GITHUB_TOKEN"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := again.Get("GITHUB_TOKEN"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := again.Delete("GITHUB_TOKEN"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("second Delete = %v, want ErrNotFound", err)
+	}
+
+	env, err := Env(again)
+	if err != nil || len(env) != 1 || env["OPENROUTER_API_KEY"] != "sk-or-3" {
+		t.Errorf("Env = %v, %v", env, err)
+	}
+}
+
+func TestFileStoreKeyFile(t *testing.T) {
+	s := newFileStore(t)
+	if err := s.Set("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	for path, want := range map[string]os.FileMode{
+		s.KeyFile:               0o600,
+		filepath.Dir(s.KeyFile): 0o700,
+		s.Path:                  0o600,
+		filepath.Dir(s.Path):    0o700,
+	} {
+		info, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != want {
+			t.Errorf("%s mode = %v, want %v", path, info.Mode().Perm(), want)
+		}
+	}
+
+	// The file is plain age, readable with the key file
+	key, err := os.Open(s.KeyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer key.Close()
+	identities, err := age.ParseIdentities(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(s.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := age.Decrypt(f, identities...); err != nil {
+		t.Errorf("age can't decrypt the store with the key file: %v", err)
+	}
+
+	// An existing key is reused, not replaced
+	before, _ := os.ReadFile(s.KeyFile)
+	if err := s.Set("B", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if after, _ := os.ReadFile(s.KeyFile); string(after) != string(before) {
+		t.Error("key file was regenerated")
+	}
+}
+
+func TestFileStorePassphrase(t *testing.T) {
+	dir := t.TempDir()
+	asked := 0
+	s := &FileStore{
+		Path:    filepath.Join(dir, "secrets.age"),
+		KeyFile: filepath.Join(dir, "secrets.key"),
+		Passphrase: func() (string, error) {
+			asked++
+			return "correct horse", nil
+		},
+	}
+	if err := s.Set("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := s.Get("A"); err != nil || value != "1" {
+		t.Fatalf("Get = %q, %v", value, err)
+	}
+	if asked != 1 {
+		t.Errorf("passphrase asked %d times, want once", asked)
+	}
+	if _, err := os.Stat(s.KeyFile); err == nil {
+		t.Error("a key file was created in passphrase mode")
+	}
+
+	wrong := &FileStore{Path: s.Path, Passphrase: func() (string, error) { return "battery staple", nil }}
+	if _, err := wrong.Get("A"); err == nil || !strings.Contains(err.Error(), "failed to decrypt") {
+		t.Errorf("Get with a wrong passphrase = %v", err)
+	}
+
+	empty := &FileStore{Path: s.Path, Passphrase: func() (string, error) { return "", nil }}
+	if _, err := empty.Get("A"); err == nil || !strings.Contains(err.Error(), "empty passphrase") {
+		t.Errorf("Get with an empty passphrase = %v", err)
+	}
+}
+
+func TestFileStoreUnreadable(t *testing.T) {
+	s := newFileStore(t)
+	if err := s.Set("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		store   func() *FileStore
+		message string
+	}{
+		{"missing key file", func() *FileStore {
+			return &FileStore{Path: s.Path, KeyFile: filepath.Join(t.TempDir(), "none.key")}
+		}, "not found"},
+		{"wrong key", func() *FileStore {
+			other := newFileStore(t)
+			if err := other.Set("B", "2"); err != nil {
+				t.Fatal(err)
+			}
+			return &FileStore{Path: s.Path, KeyFile: other.KeyFile}
+		}, "failed to decrypt"},
+		{"corrupt file", func() *FileStore {
+			path := filepath.Join(t.TempDir(), "secrets.age")
+			if err := os.WriteFile(path, []byte("not age"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			return &FileStore{Path: path, KeyFile: s.KeyFile}
+		}, "failed to decrypt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.store()
+			if _, err := store.Get("A"); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Get = %v, want an error containing %q", err, tt.message)
+			}
+			if err := store.Set("C", "3"); err == nil {
+				t.Error("Set over an unreadable store succeeded")
+			}
+		})
+	}
+}
+
+// scratchConfig points the config and state dirs at a temp dir and
+// writes config.yaml
+func scratchConfig(t *testing.T, yaml string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	t.Setenv("HIVE_CONFIG", "")
+	t.Setenv("HIVE_SECRETS_KEY_FILE", "")
+	t.Setenv("HIVE_SECRETS_PASSPHRASE", "")
+	dir := filepath.Join(home, ".config", "hive")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	scratchConfig(t, "")
+	if InUse() {
+		t.Fatal("InUse without a secrets file")
+	}
+	if _, ok := Lookup("A"); ok {
+		t.Fatal("Lookup found a secret in an empty store")
+	}
+
+	store, err := OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if value, ok := Lookup("A"); !ok || value != "1" {
+		t.Errorf("Lookup = %q, %v", value, ok)
+	}
+	if !Has("A") || Has("B") {
+		t.Error("Has disagrees with the store")
+	}
+}
+
+func TestLookupPassphrase(t *testing.T) {
+	scratchConfig(t, "secrets:\n  passphrase: true\n")
+	t.Setenv("HIVE_SECRETS_PASSPHRASE", "pw")
+	store, err := OpenDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("A", "1"); err != nil {
+		t.Fatal(err)
+	}
+	if !PassphraseProtected() || !Unattended() {
+		t.Fatal("passphrase store with HIVE_SECRETS_PASSPHRASE should be readable unattended")
+	}
+	if value, ok := Lookup("A"); !ok || value != "1" {
+		t.Errorf("Lookup = %q, %v", value, ok)
+	}
+
+	// Without the passphrase nothing prompts; the secret is just absent
+	t.Setenv("HIVE_SECRETS_PASSPHRASE", "")
+	if Unattended() {
+		t.Error("Unattended without HIVE_SECRETS_PASSPHRASE")
+	}
+	if _, ok := Lookup("A"); ok {
+		t.Error("Lookup read a passphrase store without the passphrase")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	for backend, want := range map[string]string{
+		"":               "*secrets.FileStore",
+		"file":           "*secrets.FileStore",
+		"pass":           "*secrets.PassStore",
+		"secret-service": "*secrets.SecretServiceStore",
+	} {
+		store, err := Open(config.Secrets{Backend: backend})
+		if err != nil {
+			t.Fatalf("Open(%q): %v", backend, err)
+		}
+		if got := fmt.Sprintf("%T", store); got != want {
+			t.Errorf("Open(%q) = %s, want %s", backend, got, want)
+		}
+	}
+	if _, err := Open(config.Secrets{Backend: "keychain"}); err == nil {
+		t.Error("Open accepted an unknown backend")
+	}
+}
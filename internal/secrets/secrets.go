@@ -0,0 +1,173 @@
+// Package secrets stores credentials such as OPENROUTER_API_KEY outside
+// of shell config files. Secrets are named after the environment
+// variable they are injected as when hive-mcp is launched.
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/ui"
+)
+
+// ErrNotFound is returned when a secret isn't in the store
+var ErrNotFound = errors.New("secret not found")
+
+// Store is a backend that holds secrets
+type Store interface {
+	// Name identifies the backend in messages
+	Name() string
+	Get(name string) (string, error)
+	Set(name, value string) error
+	Delete(name string) error
+	// List returns the stored secret names, sorted
+	List() ([]string, error)
+}
+
+// Backend names accepted in config.yaml's secrets.backend
+const (
+	BackendFile          = "file"
+	BackendPass          = "pass"
+	BackendSecretService = "secret-service"
+)
+
+// Backends lists the supported backend names
+func Backends() []string {
+	return []string{BackendFile, BackendPass, BackendSecretService}
+}
+
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateName checks that name can be used as an environment variable
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid secret name %q (use an environment variable name such as OPENROUTER_API_KEY)", name)
+	}
+	return nil
+}
+
+// Open returns the store selected by cfg
+func Open(cfg config.Secrets) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendFile:
+		return &FileStore{
+			Path:       FilePath(),
+			KeyFile:    keyFile(cfg),
+			Passphrase: passphraseFunc(cfg),
+		}, nil
+	case BackendPass:
+		return &PassStore{}, nil
+	case BackendSecretService:
+		return &SecretServiceStore{}, nil
+	}
+	return nil, fmt.Errorf("unknown secrets backend %q (expected %s)", cfg.Backend, strings.Join(Backends(), ", "))
+}
+
+// OpenDefault opens the store configured in config.yaml
+func OpenDefault() (Store, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return Open(cfg.Secrets)
+}
+
+// InUse reports whether secrets may need injecting: an external backend
+// is configured, or the encrypted file exists. It never decrypts.
+func InUse() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	switch cfg.Secrets.Backend {
+	case "", BackendFile:
+		_, err := os.Stat(FilePath())
+		return err == nil
+	}
+	return true
+}
+
+// PassphraseProtected reports whether the configured store is the file
+// backend encrypted with a passphrase
+func PassphraseProtected() bool {
+	cfg, err := config.Load()
+	if err != nil {
+		return false
+	}
+	backend := cfg.Secrets.Backend
+	return cfg.Secrets.Passphrase && (backend == "" || backend == BackendFile)
+}
+
+// Unattended reports whether secrets are in use and can be read without
+// prompting: a passphrase-protected store needs HIVE_SECRETS_PASSPHRASE
+func Unattended() bool {
+	return InUse() && (!PassphraseProtected() || os.Getenv("HIVE_SECRETS_PASSPHRASE") != "")
+}
+
+// Has reports whether name is stored, without prompting for a
+// passphrase; a store that can't be read counts as not having it
+func Has(name string) bool {
+	_, ok := Lookup(name)
+	return ok
+}
+
+// Lookup reads a secret like Has, for checks that run unattended
+func Lookup(name string) (string, bool) {
+	if !Unattended() {
+		return "", false
+	}
+	store, err := OpenDefault()
+	if err != nil {
+		return "", false
+	}
+	value, err := store.Get(name)
+	return value, err == nil
+}
+
+// Env returns every secret as an environment variable
+func Env(store Store) (map[string]string, error) {
+	names, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string, len(names))
+	for _, name := range names {
+		value, err := store.Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s from %s: %w", name, store.Name(), err)
+		}
+		env[name] = value
+	}
+	return env, nil
+}
+
+// passphraseFunc returns how the file store gets its passphrase, or nil
+// when it uses a key file
+func passphraseFunc(cfg config.Secrets) func() (string, error) {
+	if !cfg.Passphrase {
+		return nil
+	}
+	return func() (string, error) {
+		if pass := os.Getenv("HIVE_SECRETS_PASSPHRASE"); pass != "" {
+			return pass, nil
+		}
+		if !ui.IsTerminal() {
+			return "", errors.New("secrets are passphrase protected; set HIVE_SECRETS_PASSPHRASE")
+		}
+		return ui.PasswordPrompt("Secrets passphrase")
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
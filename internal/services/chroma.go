@@ -0,0 +1,166 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+const chromaHeartbeatURL = "http://localhost:8000/api/v2/heartbeat"
+
+// Chroma manages the Chroma vector database container
+type Chroma struct{}
+
+func (c *Chroma) Name() string {
+	return "chroma"
+}
+
+func (c *Chroma) ReadyTimeout() time.Duration {
+	return 60 * time.Second
+}
+
+func (c *Chroma) Status() Status {
+	status := Status{Service: c.Name(), Endpoint: "localhost:8000"}
+	status.Running, status.Message = httpHealthy(chromaHeartbeatURL)
+	return status
+}
+
+// usesCompose reports whether the hive-mcp checkout provides a compose
+// file, in which case Chroma is managed the same way ChromaStep does it
+func usesCompose() bool {
+	dir := hiveMCPDir()
+	for _, name := range []string{"docker-compose.yml", "docker-compose.yaml", "compose.yml", "compose.yaml"} {
+		if util.FileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Start runs Chroma through docker compose like setup does, falling
+// back to a standalone container when there's no compose file
+func (c *Chroma) Start() error {
+	if usesCompose() {
+		return (&setup.ChromaStep{HiveMCPDir: hiveMCPDir()}).Run()
+	}
+
+	if err := exec.Command("docker", "info").Run(); err != nil {
+		return fmt.Errorf("docker is not running: %w", err)
+	}
+
+	// Check if container already exists (stopped)
+	if id := standaloneContainer(); id != "" {
+		if err := exec.Command("docker", "start", id).Run(); err == nil {
+			return nil
+		}
+	}
+
+	// Run new container
+	cmd := exec.Command("docker", "run", "-d",
+		"--name", "chroma",
+		"-p", "8000:8000",
+		"-v", "chroma-data:/chroma/chroma",
+		chroma.Image(hiveMCPDir()))
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to start Chroma container: %w", err)
+	}
+	return nil
+}
+
+func (c *Chroma) Stop() error {
+	id := chromaContainer()
+	if id == "" {
+		return fmt.Errorf("no Chroma container found")
+	}
+
+	cmd := exec.Command("docker", "stop", id)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to stop Chroma: %w", err)
+	}
+	return nil
+}
+
+func (c *Chroma) Logs(follow bool, lines int) error {
+	id := chromaContainer()
+	if id == "" {
+		return fmt.Errorf("no Chroma container found")
+	}
+
+	args := []string{"logs"}
+	if follow {
+		args = append(args, "--follow")
+	}
+	if lines > 0 {
+		args = append(args, "--tail", strconv.Itoa(lines))
+	}
+	cmd := exec.Command("docker", append(args, id)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stdout // docker logs replays the container's stderr here
+	return cmd.Run()
+}
+
+// chromaContainer returns the ID of the Chroma container, preferring
+// the compose-managed one
+func chromaContainer() string {
+	if usesCompose() {
+		cmd := exec.Command("docker", "compose", "ps", "-a", "-q", "chroma")
+		cmd.Dir = hiveMCPDir()
+		if out, err := cmd.Output(); err == nil {
+			if id := strings.TrimSpace(string(out)); id != "" {
+				return id
+			}
+		}
+	}
+	return standaloneContainer()
+}
+
+// standaloneContainer returns the ID of a container named "chroma"
+func standaloneContainer() string {
+	out, err := exec.Command("docker", "ps", "-a", "-q", "--filter", "name=^chroma$").Output()
+	if err != nil {
+		return ""
+	}
+	fields := strings.Fields(string(out))
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// HasChromaContainer reports whether a Chroma container exists,
+// running or not
+func HasChromaContainer() bool {
+	return chromaContainer() != ""
+}
+
+// RemoveChroma stops and removes the Chroma container the same way it
+// was created. The data volume is kept.
+func RemoveChroma() error {
+	if usesCompose() {
+		if err := (&setup.ChromaStep{HiveMCPDir: hiveMCPDir()}).Rollback(); err != nil {
+			return fmt.Errorf("docker compose down failed: %w", err)
+		}
+	}
+
+	// A standalone container may exist alongside or instead of the
+	// compose-managed one
+	id := standaloneContainer()
+	if id == "" {
+		return nil
+	}
+	out, err := exec.Command("docker", "rm", "--force", id).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to remove Chroma container: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// messagesExpr returns the contents of the daemon's *Messages* buffer
+const messagesExpr = `(with-current-buffer "*Messages*" (buffer-substring-no-properties (point-min) (point-max)))`
+
+// Emacs manages the Emacs daemon hosting hive-mcp
+type Emacs struct{}
+
+func (e *Emacs) Name() string {
+	return "emacs"
+}
+
+func (e *Emacs) ReadyTimeout() time.Duration {
+	return setup.DefaultEmacsReadyTimeout
+}
+
+func (e *Emacs) Status() Status {
+	status := Status{Service: e.Name()}
+
+	out, err := emacs.Client(emacs.Server(), "--eval", "(emacs-pid)").Output()
+	pid := strings.TrimSpace(string(out))
+	if err != nil || pid == "" || pid == "nil" {
+		status.Message = fmt.Sprintf("Emacs %s not running", emacs.DisplayName(emacs.Server()))
+		return status
+	}
+
+	status.Running = true
+	status.Endpoint = fmt.Sprintf("PID %s", pid)
+	return status
+}
+
+func (e *Emacs) Start() error {
+	return (&setup.EmacsDaemonStep{}).Run()
+}
+
+// WaitReady waits for hive-mcp to be loaded, not just for the server
+// to answer
+func (e *Emacs) WaitReady() error {
+	return (&setup.EmacsDaemonStep{}).WaitReady()
+}
+
+func (e *Emacs) Stop() error {
+	return (&setup.EmacsDaemonStep{}).Rollback()
+}
+
+// Logs prints the daemon's *Messages* buffer. With follow, new
+// messages are polled until the daemon exits.
+func (e *Emacs) Logs(follow bool, lines int) error {
+	text, err := emacsMessages()
+	if err != nil {
+		return err
+	}
+	fmt.Print(tailLines(text, lines))
+
+	seen := len(text)
+	for follow {
+		time.Sleep(time.Second)
+		text, err = emacsMessages()
+		if err != nil {
+			return nil // daemon exited
+		}
+		if len(text) < seen {
+			// Buffer was truncated (message-log-max); start over
+			seen = 0
+		}
+		fmt.Print(text[seen:])
+		seen = len(text)
+	}
+	return nil
+}
+
+func emacsMessages() (string, error) {
+	out, err := emacs.Client(emacs.Server(), "--eval", messagesExpr).Output()
+	if err != nil {
+		return "", fmt.Errorf("Emacs daemon not running")
+	}
+	return unquoteElisp(string(out)), nil
+}
+
+// unquoteElisp decodes a string printed by emacsclient --eval, which
+// uses prin1 syntax: surrounding quotes with \" and \\ escapes and
+// literal newlines
+func unquoteElisp(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	s = s[1 : len(s)-1]
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+			if s[i] == 'n' {
+				b.WriteByte('\n')
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// tailLines returns the last n lines of text (all of it when n <= 0)
+func tailLines(text string, n int) string {
+	if n <= 0 {
+		return text
+	}
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "")
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+const ollamaTagsURL = "http://localhost:11434/api/tags"
+
+// Ollama manages a user-level `ollama serve` process
+type Ollama struct{}
+
+func (o *Ollama) Name() string {
+	return "ollama"
+}
+
+func (o *Ollama) ReadyTimeout() time.Duration {
+	return 30 * time.Second
+}
+
+func (o *Ollama) Status() Status {
+	status := Status{Service: o.Name(), Endpoint: "localhost:11434"}
+	status.Running, status.Message = httpHealthy(ollamaTagsURL)
+	return status
+}
+
+func (o *Ollama) logFile() string {
+	return filepath.Join(util.StateDir(), "ollama.log")
+}
+
+func (o *Ollama) pidFile() string {
+	return filepath.Join(util.StateDir(), "ollama.pid")
+}
+
+// Start runs `ollama serve` in its own session with output going to a
+// log file, so it survives the CLI exiting and `logs` has something to
+// show
+func (o *Ollama) Start() error {
+	if _, err := exec.LookPath("ollama"); err != nil {
+		return fmt.Errorf("ollama not installed - please install from https://ollama.ai")
+	}
+
+	if err := os.MkdirAll(util.StateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	logFile, err := os.OpenFile(o.logFile(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", o.logFile(), err)
+	}
+	defer logFile.Close()
+
+	cmd := exec.Command("ollama", "serve")
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start ollama serve: %w", err)
+	}
+
+	pid := strconv.Itoa(cmd.Process.Pid)
+	if err := os.WriteFile(o.pidFile(), []byte(pid+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", o.pidFile(), err)
+	}
+	return cmd.Process.Release()
+}
+
+// Stop terminates the process started by Start, falling back to any
+// `ollama serve` owned by the user
+func (o *Ollama) Stop() error {
+	if data, err := os.ReadFile(o.pidFile()); err == nil {
+		os.Remove(o.pidFile())
+		if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+			if err := syscall.Kill(pid, syscall.SIGTERM); err == nil {
+				return nil
+			}
+		}
+	}
+
+	if err := exec.Command("pkill", "-u", strconv.Itoa(os.Getuid()), "-f", "ollama serve").Run(); err != nil {
+		return fmt.Errorf("no ollama serve process found - it may be managed by the system service manager")
+	}
+	return nil
+}
+
+func (o *Ollama) Logs(follow bool, lines int) error {
+	if !util.FileExists(o.logFile()) {
+		return fmt.Errorf("no log file at %s - Ollama was not started by hive", o.logFile())
+	}
+
+	if lines <= 0 {
+		lines = 100
+	}
+	args := []string{"-n", strconv.Itoa(lines)}
+	if follow {
+		args = append(args, "-f")
+	}
+	cmd := exec.Command("tail", append(args, o.logFile())...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,185 @@
+// Package services manages the lifecycle of the background services
+// hive-mcp depends on: the Emacs daemon, Chroma and Ollama
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// Status describes the observed state of a service
+type Status struct {
+	Service  string
+	Running  bool
+	Endpoint string
+	Message  string
+}
+
+// Service controls a single background service
+type Service interface {
+	Name() string
+	Status() Status
+	Start() error
+	Stop() error
+	Logs(follow bool, lines int) error
+	ReadyTimeout() time.Duration
+}
+
+// All returns every managed service in startup order
+func All() []Service {
+	return []Service{&Emacs{}, &Chroma{}, &Ollama{}}
+}
+
+// Names returns the names of all managed services
+func Names() []string {
+	var names []string
+	for _, svc := range All() {
+		names = append(names, svc.Name())
+	}
+	return names
+}
+
+// Get returns the service with the given name
+func Get(name string) (Service, error) {
+	for _, svc := range All() {
+		if svc.Name() == name {
+			return svc, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown service %q (expected one of: %s)", name, strings.Join(Names(), ", "))
+}
+
+// readyWaiter is a service with a readiness check stricter than its
+// status, such as Emacs having loaded hive-mcp
+type readyWaiter interface {
+	WaitReady() error
+}
+
+// Start starts a service unless it is already running and waits for
+// it to become ready. Services with an installed systemd unit are
+// started through systemctl.
+func Start(svc Service) error {
+	if svc.Status().Running {
+		return nil
+	}
+
+	var err error
+	if unit := activeUnit(svc); unit != "" {
+		err = systemctl("start", unit)
+	} else {
+		err = svc.Start()
+	}
+	if err != nil {
+		return err
+	}
+	if w, ok := svc.(readyWaiter); ok {
+		return w.WaitReady()
+	}
+	return WaitReady(svc, svc.ReadyTimeout())
+}
+
+// Stop stops a service if it is running
+func Stop(svc Service) error {
+	if unit := activeUnit(svc); unit != "" {
+		return systemctl("stop", unit)
+	}
+	if !svc.Status().Running {
+		return nil
+	}
+	return svc.Stop()
+}
+
+// Logs prints a service's logs, reading the journal for services
+// managed by a systemd unit
+func Logs(svc Service, follow bool, lines int) error {
+	unit := activeUnit(svc)
+	if unit == "" {
+		return svc.Logs(follow, lines)
+	}
+
+	args := []string{"--user", "-u", unit, "--no-pager"}
+	if lines > 0 {
+		args = append(args, "-n", strconv.Itoa(lines))
+	}
+	if follow {
+		args = append(args, "--follow")
+	}
+	cmd := exec.Command("journalctl", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Restart stops and starts a service, waiting for readiness
+func Restart(svc Service) error {
+	if err := Stop(svc); err != nil {
+		return err
+	}
+	// Give the old process a moment to release its port or socket
+	if err := waitStopped(svc, 10*time.Second); err != nil {
+		return err
+	}
+	return Start(svc)
+}
+
+// WaitReady polls the service's health with backoff until it reports
+// running or the timeout expires
+func WaitReady(svc Service, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	delay := 250 * time.Millisecond
+	for {
+		status := svc.Status()
+		if status.Running {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			msg := status.Message
+			if msg == "" {
+				msg = "not responding"
+			}
+			return fmt.Errorf("%s not ready after %s: %s", svc.Name(), timeout, msg)
+		}
+		time.Sleep(delay)
+		if delay < 2*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+func waitStopped(svc Service, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for svc.Status().Running {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s still running after %s", svc.Name(), timeout)
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
+	return nil
+}
+
+// hiveMCPDir returns the hive-mcp checkout used by setup
+func hiveMCPDir() string {
+	return util.ExpandPath(util.GetEnv("HIVE_MCP_DIR", setup.DefaultHiveMCPDir()))
+}
+
+// httpHealthy reports whether url answers 200 OK
+func httpHealthy(url string) (bool, string) {
+	client := &http.Client{Timeout: 2 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return false, "not responding"
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Sprintf("unhealthy (status %d)", resp.StatusCode)
+	}
+	return true, ""
+}
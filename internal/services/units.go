@@ -0,0 +1,334 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// Unit is a rendered systemd --user unit file
+type Unit struct {
+	Service string // managed service name ("emacs", "chroma", "ollama")
+	Name    string // unit file name
+	Content string
+}
+
+// UnitOptions holds the values units are rendered with
+type UnitOptions struct {
+	EmacsServer  string
+	ChromaVolume string
+	ChromaImage  string
+	HiveMCPDir   string
+	Env          map[string]string
+
+	// Absolute paths of the binaries the units execute
+	EmacsBin       string
+	EmacsClientBin string
+	DockerBin      string
+	OllamaBin      string
+}
+
+// UnitName returns the systemd unit managing a service
+func UnitName(service string) string {
+	return "hive-" + service + ".service"
+}
+
+// UnitDir returns the systemd user unit directory
+func UnitDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "systemd", "user")
+}
+
+// HasSystemd reports whether a systemd user manager is available
+func HasSystemd() bool {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return false
+	}
+	return exec.Command("systemctl", "--user", "show-environment").Run() == nil
+}
+
+// DefaultUnitOptions resolves unit options from the hive config and
+// the binaries on PATH
+func DefaultUnitOptions() (UnitOptions, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return UnitOptions{}, err
+	}
+
+	opts := UnitOptions{
+		EmacsServer: emacs.Server(),
+		HiveMCPDir:  hiveMCPDir(),
+		Env:         cfg.Env,
+	}
+
+	opts.ChromaVolume, err = chroma.ResolveVolume("")
+	if err != nil {
+		opts.ChromaVolume = chroma.DefaultVolume
+	}
+	opts.ChromaImage = chroma.Image(opts.HiveMCPDir)
+
+	for bin, dst := range map[string]*string{
+		"emacs":       &opts.EmacsBin,
+		"emacsclient": &opts.EmacsClientBin,
+		"docker":      &opts.DockerBin,
+		"ollama":      &opts.OllamaBin,
+	} {
+		if path, err := exec.LookPath(bin); err == nil {
+			*dst, _ = filepath.Abs(path)
+		}
+	}
+	return opts, nil
+}
+
+// RenderUnits renders the unit files for every service whose binary
+// was found. The Emacs daemon is ordered after the Chroma and Ollama
+// units, since hive-mcp connects to them when it loads.
+func RenderUnits(opts UnitOptions) []Unit {
+	env := renderEnv(opts)
+	var units []Unit
+
+	var backends []string
+	if opts.DockerBin != "" {
+		backends = append(backends, UnitName("chroma"))
+	}
+	if opts.OllamaBin != "" {
+		backends = append(backends, UnitName("ollama"))
+	}
+	deps := ""
+	if len(backends) > 0 {
+		deps = fmt.Sprintf("Wants=%[1]s\nAfter=%[1]s\n", strings.Join(backends, " "))
+	}
+
+	if opts.EmacsBin != "" && opts.EmacsClientBin != "" {
+		units = append(units, Unit{
+			Service: "emacs",
+			Name:    UnitName("emacs"),
+			Content: fmt.Sprintf(`[Unit]
+Description=Emacs daemon for hive-mcp (%[1]s)
+Documentation=https://github.com/hive-agi/hive-mcp
+%[6]s
+[Service]
+Type=simple
+%[5]sExecStart=%[2]s %[3]s
+ExecStop=%[4]s
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`, emacs.DisplayName(opts.EmacsServer), opts.EmacsBin,
+				strings.Replace(emacs.DaemonFlag(opts.EmacsServer), "--daemon", "--fg-daemon", 1),
+				strings.Join(append([]string{opts.EmacsClientBin}, emacs.ClientArgs(opts.EmacsServer, "--eval", `"(kill-emacs)"`)...), " "),
+				env, deps),
+		})
+	}
+
+	if opts.DockerBin != "" {
+		units = append(units, Unit{
+			Service: "chroma",
+			Name:    UnitName("chroma"),
+			Content: fmt.Sprintf(`[Unit]
+Description=Chroma vector database for hive-mcp
+Documentation=https://docs.trychroma.com
+
+[Service]
+Type=simple
+%[3]sExecStartPre=-%[1]s rm -f chroma
+ExecStart=%[1]s run --rm --name chroma -p 8000:8000 -v %[2]s:/chroma/chroma %[4]s
+ExecStop=%[1]s stop chroma
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=default.target
+`, opts.DockerBin, opts.ChromaVolume, env, opts.ChromaImage),
+		})
+	}
+
+	if opts.OllamaBin != "" {
+		units = append(units, Unit{
+			Service: "ollama",
+			Name:    UnitName("ollama"),
+			Content: fmt.Sprintf(`[Unit]
+Description=Ollama embedding server for hive-mcp
+Documentation=https://ollama.ai
+
+[Service]
+Type=simple
+%[2]sExecStart=%[1]s serve
+Restart=on-failure
+RestartSec=5
+
+[Install]
+WantedBy=default.target
+`, opts.OllamaBin, env),
+		})
+	}
+
+	return units
+}
+
+// renderEnv renders Environment= lines in a deterministic order
+func renderEnv(opts UnitOptions) string {
+	env := map[string]string{}
+	if opts.HiveMCPDir != "" {
+		env["HIVE_MCP_DIR"] = opts.HiveMCPDir
+		env["BB_MCP_DIR"] = opts.HiveMCPDir
+	}
+	for k, v := range opts.Env {
+		env[k] = v
+	}
+
+	keys := make([]string, 0, len(env))
+	for k := range env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "Environment=%s\n", systemdQuote(k+"="+env[k]))
+	}
+	return b.String()
+}
+
+// systemdQuote quotes a value for a unit file, escaping specifiers
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "%", "%%")
+	return `"` + s + `"`
+}
+
+// WriteUnits writes units into dir, creating it if needed
+func WriteUnits(dir string, units []Unit) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, unit := range units {
+		path := filepath.Join(dir, unit.Name)
+		if err := os.WriteFile(path, []byte(unit.Content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// EnableUnits reloads the user manager and enables and starts units.
+// Services already running outside systemd are stopped first so the
+// units can take over their ports and sockets.
+func EnableUnits(units []Unit) error {
+	if err := systemctl("daemon-reload"); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(units))
+	for _, unit := range units {
+		svc, err := Get(unit.Service)
+		if err == nil && UnitState(unit.Name).Active != "active" && svc.Status().Running {
+			if err := svc.Stop(); err != nil {
+				return fmt.Errorf("failed to stop %s before handing it to systemd: %w", unit.Service, err)
+			}
+		}
+		names = append(names, unit.Name)
+	}
+
+	return systemctl(append([]string{"enable", "--now"}, names...)...)
+}
+
+// EnableUnit enables and starts a single installed unit, clearing a
+// previous failed state
+func EnableUnit(name string) error {
+	exec.Command("systemctl", "--user", "reset-failed", name).Run()
+	return systemctl("enable", "--now", name)
+}
+
+// UnitStatus describes a unit as seen by the user manager
+type UnitStatus struct {
+	Installed bool
+	Enabled   string // output of is-enabled
+	Active    string // output of is-active
+}
+
+// UnitState queries systemd for the state of a unit
+func UnitState(name string) UnitStatus {
+	status := UnitStatus{
+		Installed: util.FileExists(filepath.Join(UnitDir(), name)),
+	}
+	out, _ := exec.Command("systemctl", "--user", "is-enabled", name).Output()
+	status.Enabled = strings.TrimSpace(string(out))
+	out, _ = exec.Command("systemctl", "--user", "is-active", name).Output()
+	status.Active = strings.TrimSpace(string(out))
+	return status
+}
+
+// activeUnit returns the unit managing svc if one is installed and a
+// systemd user manager is running, so lifecycle actions go through
+// systemctl instead of fighting its restart policy
+func activeUnit(svc Service) string {
+	name := UnitName(svc.Name())
+	if !util.FileExists(filepath.Join(UnitDir(), name)) {
+		return ""
+	}
+	if !HasSystemd() {
+		return ""
+	}
+	return name
+}
+
+func systemctl(args ...string) error {
+	cmd := exec.Command("systemctl", append([]string{"--user"}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("systemctl --user %s failed: %w", strings.Join(args, " "), err)
+	}
+	return nil
+}
+
+// InstalledUnits returns the names of the hive units present in the
+// unit directory
+func InstalledUnits() []string {
+	var names []string
+	for _, svc := range All() {
+		name := UnitName(svc.Name())
+		if util.FileExists(filepath.Join(UnitDir(), name)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RemoveUnits disables and stops the named units, deletes their files
+// and reloads the user manager
+func RemoveUnits(names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	if HasSystemd() {
+		if err := systemctl(append([]string{"disable", "--now"}, names...)...); err != nil {
+			return err
+		}
+	}
+	for _, name := range names {
+		path := filepath.Join(UnitDir(), name)
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	if HasSystemd() {
+		return systemctl("daemon-reload")
+	}
+	return nil
+}
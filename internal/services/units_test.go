@@ -0,0 +1,96 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testUnitOptions() UnitOptions {
+	return UnitOptions{
+		EmacsServer:    "hive",
+		ChromaVolume:   "hive-chroma",
+		ChromaImage:    "chromadb/chroma:1.0.12",
+		HiveMCPDir:     "/home/u/hive-mcp",
+		Env:            map[string]string{"OLLAMA_HOST": "127.0.0.1:11434", "NOTE": `50% "quoted"`},
+		EmacsBin:       "/usr/bin/emacs",
+		EmacsClientBin: "/usr/bin/emacsclient",
+		DockerBin:      "/usr/bin/docker",
+		OllamaBin:      "/usr/local/bin/ollama",
+	}
+}
+
+func TestRenderAndWriteUnits(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "systemd", "user")
+	if err := WriteUnits(dir, RenderUnits(testUnitOptions())); err != nil {
+		t.Fatalf("WriteUnits: %v", err)
+	}
+
+	env := []string{
+		`Environment="BB_MCP_DIR=/home/u/hive-mcp"`,
+		`Environment="HIVE_MCP_DIR=/home/u/hive-mcp"`,
+		`Environment="NOTE=50%% \"quoted\""`,
+		`Environment="OLLAMA_HOST=127.0.0.1:11434"`,
+	}
+	tests := []struct {
+		service string
+		want    []string
+	}{
+		{"emacs", append([]string{
+			"ExecStart=/usr/bin/emacs --fg-daemon=hive",
+			"ExecStop=/usr/bin/emacsclient --socket-name=hive --eval \"(kill-emacs)\"",
+			"Wants=hive-chroma.service hive-ollama.service",
+			"After=hive-chroma.service hive-ollama.service",
+			"WantedBy=default.target",
+		}, env...)},
+		{"chroma", append([]string{
+			"ExecStartPre=-/usr/bin/docker rm -f chroma",
+			"ExecStart=/usr/bin/docker run --rm --name chroma -p 8000:8000 -v hive-chroma:/chroma/chroma chromadb/chroma:1.0.12",
+			"ExecStop=/usr/bin/docker stop chroma",
+			"WantedBy=default.target",
+		}, env...)},
+		{"ollama", append([]string{
+			"ExecStart=/usr/local/bin/ollama serve",
+			"WantedBy=default.target",
+		}, env...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.service, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, UnitName(tt.service)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := strings.Split(string(data), "\n")
+			for _, want := range tt.want {
+				if !slices.Contains(lines, want) {
+					t.Errorf("%s lacks line %q:\n%s", UnitName(tt.service), want, data)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderUnitsMissingBinaries(t *testing.T) {
+	opts := testUnitOptions()
+	opts.DockerBin = ""
+	opts.OllamaBin = ""
+	units := RenderUnits(opts)
+	if len(units) != 1 || units[0].Service != "emacs" {
+		t.Fatalf("RenderUnits = %v, want only the emacs unit", units)
+	}
+	if strings.Contains(units[0].Content, "After=") || strings.Contains(units[0].Content, "Wants=") {
+		t.Errorf("emacs unit depends on units that weren't rendered:\n%s", units[0].Content)
+	}
+
+	opts.EmacsServer = ""
+	if content := RenderUnits(opts)[0].Content; !strings.Contains(content, "ExecStart=/usr/bin/emacs --fg-daemon\n") {
+		t.Errorf("default server unit:\n%s", content)
+	}
+
+	opts.EmacsClientBin = ""
+	if units := RenderUnits(opts); len(units) != 0 {
+		t.Errorf("rendered %d units without emacsclient", len(units))
+	}
+}
@@ -2,16 +2,42 @@ package setup
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
 // CloneStep clones required repositories
 type CloneStep struct {
 	HiveMCPDir string // Target directory for hive-mcp
+
+	// URL is the repository to clone: HTTPS, SSH or a local path.
+	// Empty means upstream hive-mcp.
+	URL string
+
+	// Ref pins a tag, branch or commit SHA; empty means the remote's
+	// default branch
+	Ref string
+
+	// Depth makes a shallow clone of that many commits; 0 clones the
+	// full history
+	Depth int
+
+	// Source clones from a local path or git bundle instead of URL;
+	// origin is pointed back at URL afterwards
+	Source string
 }
 
+// HiveMCPRepo is the upstream hive-mcp repository
+const HiveMCPRepo = "https://github.com/hive-agi/hive-mcp.git"
+
+// shaPattern matches abbreviated and full commit SHAs
+var shaPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
 // DefaultHiveMCPDir returns the default installation directory
 func DefaultHiveMCPDir() string {
 	home, _ := os.UserHomeDir()
@@ -29,15 +55,46 @@ func (s *CloneStep) targetDir() string {
 	return DefaultHiveMCPDir()
 }
 
+func (s *CloneStep) url() string {
+	if s.URL != "" {
+		return s.URL
+	}
+	return HiveMCPRepo
+}
+
 func (s *CloneStep) Check() (bool, error) {
 	dir := s.targetDir()
 
 	// Check if directory exists and has .git
 	gitDir := filepath.Join(dir, ".git")
-	if _, err := os.Stat(gitDir); err == nil {
-		return true, nil
+	if _, err := os.Stat(gitDir); err != nil {
+		return false, nil
+	}
+
+	if problem := s.Verify(); problem != "" {
+		return false, fmt.Errorf("%s", problem)
 	}
-	return false, nil
+	return true, nil
+}
+
+// Verify compares an existing checkout with the configured URL and
+// ref, returning a description of the mismatch or "" when it matches.
+// The remote is only compared when a URL is configured, so checkouts
+// of forks made by hand keep working.
+func (s *CloneStep) Verify() string {
+	dir := s.targetDir()
+	co, err := ReadCheckout(dir)
+	if err != nil {
+		return err.Error()
+	}
+
+	if s.URL != "" && !SameRemote(co.Remote, s.URL) {
+		return fmt.Sprintf("checkout at %s has origin %s, config expects %s", dir, co.Remote, s.URL)
+	}
+	if s.Ref != "" && !co.OnRef(s.Ref) {
+		return fmt.Sprintf("checkout on ref %s, config expects %s", co.Ref, s.Ref)
+	}
+	return ""
 }
 
 func (s *CloneStep) Run() error {
@@ -49,20 +106,131 @@ func (s *CloneStep) Run() error {
 		return fmt.Errorf("failed to create parent directory: %w", err)
 	}
 
+	source := s.url()
+	if s.Source != "" {
+		source = s.Source
+	}
+
 	// Clone repository (no submodules needed - deps fetched via git deps)
-	cmd := exec.Command("git", "clone",
-		"https://github.com/hive-agi/hive-mcp.git",
-		dir)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	isSHA := shaPattern.MatchString(s.Ref)
+	// Servers only serve full SHAs by name, so an abbreviated SHA needs
+	// the full history to resolve
+	fetchSHA := isSHA && len(s.Ref) == 40 && s.Source == ""
+	args := []string{"clone"}
+	if s.Depth > 0 && s.Source == "" && (!isSHA || fetchSHA) {
+		args = append(args, "--depth", strconv.Itoa(s.Depth))
+	}
+	switch {
+	case isSHA:
+		// --branch only takes names; fetch the commit after cloning
+		args = append(args, "--no-checkout")
+	case s.Ref != "":
+		args = append(args, "--branch", s.Ref)
+	}
+	args = append(args, source, dir)
 
-	if err := cmd.Run(); err != nil {
+	if err := runGit(args...); err != nil {
 		return fmt.Errorf("git clone failed: %w", err)
 	}
 
+	if isSHA {
+		if fetchSHA {
+			fetch := []string{"-C", dir, "fetch", "origin", s.Ref}
+			if s.Depth > 0 {
+				fetch = append(fetch, "--depth", strconv.Itoa(s.Depth))
+			}
+			if err := runGit(fetch...); err != nil {
+				return fmt.Errorf("failed to fetch %s: %w", s.Ref, err)
+			}
+		}
+		if err := runGit("-C", dir, "checkout", "--detach", s.Ref); err != nil {
+			return fmt.Errorf("failed to check out %s: %w", s.Ref, err)
+		}
+	}
+
+	if s.Source != "" {
+		if err := exec.Command("git", "-C", dir, "remote", "set-url", "origin", s.url()).Run(); err != nil {
+			return fmt.Errorf("failed to set origin: %w", err)
+		}
+	}
 	return nil
 }
 
+func runGit(args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// Checkout describes an existing git checkout
+type Checkout struct {
+	Dir    string
+	Remote string // origin URL
+	Ref    string // branch, tag at HEAD, or short SHA when detached
+	Commit string // full SHA of HEAD
+}
+
+// ReadCheckout inspects the checkout in dir
+func ReadCheckout(dir string) (*Checkout, error) {
+	git := func(args ...string) string {
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	co := &Checkout{Dir: dir, Commit: git("rev-parse", "HEAD")}
+	if co.Commit == "" {
+		return nil, fmt.Errorf("%s is not a git checkout", dir)
+	}
+	co.Remote = git("remote", "get-url", "origin")
+
+	co.Ref = git("symbolic-ref", "--short", "-q", "HEAD")
+	if co.Ref == "" {
+		co.Ref = git("describe", "--tags", "--exact-match", "HEAD")
+	}
+	if co.Ref == "" {
+		co.Ref = co.Commit[:12]
+	}
+	return co, nil
+}
+
+// OnRef reports whether the checkout is on ref: the current branch,
+// or a tag or commit resolving to HEAD
+func (c *Checkout) OnRef(ref string) bool {
+	if ref == c.Ref {
+		return true
+	}
+	out, err := exec.Command("git", "-C", c.Dir, "rev-parse", "-q", "--verify", ref+"^{commit}").Output()
+	return err == nil && strings.TrimSpace(string(out)) == c.Commit
+}
+
+// SameRemote reports whether two remote URLs name the same repository,
+// treating HTTPS and SSH forms of a host path as equal
+func SameRemote(a, b string) bool {
+	return normalizeRemote(a) == normalizeRemote(b)
+}
+
+func normalizeRemote(remote string) string {
+	r := strings.TrimSuffix(strings.TrimSuffix(remote, "/"), ".git")
+	if u, err := url.Parse(r); err == nil && u.Scheme != "" && u.Host != "" {
+		return strings.ToLower(u.Hostname()) + "/" + strings.TrimPrefix(u.Path, "/")
+	}
+	// scp-like SSH: git@host:org/repo
+	if at := strings.Index(r, "@"); at >= 0 {
+		if host, path, ok := strings.Cut(r[at+1:], ":"); ok {
+			return strings.ToLower(host) + "/" + path
+		}
+	}
+	// Local path
+	if abs, err := filepath.Abs(expandPath(r)); err == nil {
+		return abs
+	}
+	return r
+}
+
 func (s *CloneStep) Rollback() error {
 	dir := s.targetDir()
 
@@ -73,9 +241,12 @@ func (s *CloneStep) Rollback() error {
 	return nil
 }
 
-// CloneDepsStep handles downloading Clojure dependencies
+// CloneDepsStep handles downloading Clojure dependencies. It
+// fingerprints deps.edn and bb.edn after each successful prep and
+// only re-runs the parts whose dependencies changed.
 type CloneDepsStep struct {
 	HiveMCPDir string
+	Aliases    []string // extra deps.edn aliases, defaults to deps.aliases from the config
 }
 
 func (s *CloneDepsStep) Name() string {
@@ -89,26 +260,35 @@ func (s *CloneDepsStep) targetDir() string {
 	return DefaultHiveMCPDir()
 }
 
+func (s *CloneDepsStep) aliases() []string {
+	if s.Aliases != nil {
+		return normalizeAliases(s.Aliases)
+	}
+	return normalizeAliases(configuredAliases())
+}
+
 func (s *CloneDepsStep) Check() (bool, error) {
-	// Dependencies should be re-checked each time
-	// Could check for .cpcache but that's fragile
-	return false, nil
+	units, err := depsUnits(s.targetDir(), s.aliases())
+	if err != nil {
+		// Let Run report the problem
+		return false, nil
+	}
+	return len(staleUnits(s.targetDir(), units)) == 0, nil
 }
 
 func (s *CloneDepsStep) Run() error {
 	dir := s.targetDir()
-
-	// Run clojure -P to download dependencies
-	cmd := exec.Command("clojure", "-P")
-	cmd.Dir = dir
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("clojure -P failed: %w", err)
+	units, err := depsUnits(dir, s.aliases())
+	if err != nil {
+		return err
 	}
 
-	return nil
+	stale := staleUnits(dir, units)
+	if len(stale) == 0 {
+		// Forced re-run, e.g. by hive update
+		stale = units
+	}
+	return prepareUnits(dir, stale)
 }
 
 func (s *CloneDepsStep) Rollback() error {
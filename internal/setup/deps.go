@@ -0,0 +1,237 @@
+package setup
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// depsUnit is one set of dependencies prepared by a single command:
+// the default classpath, a deps.edn alias or bb.edn
+type depsUnit struct {
+	Name        string // "deps", ":alias" or "bb"
+	Fingerprint string
+	Args        []string
+}
+
+// depsUnits fingerprints deps.edn and bb.edn in dir. An alias's
+// fingerprint covers the top-level deps too, since its classpath
+// includes them.
+func depsUnits(dir string, aliases []string) ([]depsUnit, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "deps.edn"))
+	if err != nil {
+		return nil, err
+	}
+	toks, err := ednTokens(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deps.edn: %w", err)
+	}
+	entries, err := ednMapEntries(toks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read deps.edn: %w", err)
+	}
+
+	base := sha256.New()
+	defined := make(map[string]string)
+	for _, e := range entries {
+		if e.Key != ":aliases" {
+			fmt.Fprintf(base, "%s %s\n", e.Key, e.Value)
+			continue
+		}
+		aliasToks, err := ednTokens(e.Value)
+		if err != nil {
+			return nil, err
+		}
+		aliasEntries, err := ednMapEntries(aliasToks)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read deps.edn :aliases: %w", err)
+		}
+		for _, a := range aliasEntries {
+			defined[a.Key] = a.Value
+		}
+	}
+	baseSum := hex.EncodeToString(base.Sum(nil))
+
+	units := []depsUnit{{Name: "deps", Fingerprint: baseSum, Args: []string{"clojure", "-P"}}}
+	for _, alias := range aliases {
+		value, ok := defined[alias]
+		if !ok {
+			return nil, fmt.Errorf("alias %s is not defined in deps.edn", alias)
+		}
+		units = append(units, depsUnit{
+			Name:        alias,
+			Fingerprint: sha256Hex(baseSum + "\n" + value),
+			Args:        []string{"clojure", "-P", "-A" + alias},
+		})
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "bb.edn")); err == nil {
+		toks, err := ednTokens(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read bb.edn: %w", err)
+		}
+		units = append(units, depsUnit{
+			Name:        "bb",
+			Fingerprint: sha256Hex(strings.Join(toks, " ")),
+			Args:        []string{"bb", "prepare"},
+		})
+	}
+	return units, nil
+}
+
+// normalizeAliases turns config entries like "dev" or ":dev:test"
+// into a list of keywords
+func normalizeAliases(entries []string) []string {
+	var aliases []string
+	for _, entry := range entries {
+		entry = strings.TrimPrefix(strings.TrimSpace(entry), "-A")
+		for _, name := range strings.Split(entry, ":") {
+			if name != "" {
+				aliases = append(aliases, ":"+name)
+			}
+		}
+	}
+	return aliases
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// depsState records what was prepared for a checkout
+type depsState struct {
+	Units      map[string]string `json:"units"`      // unit name -> fingerprint
+	Classpaths []string          `json:"classpaths"` // valid .cpcache files afterwards
+}
+
+func depsStateFile() string {
+	return filepath.Join(util.StateDir(), "deps-fingerprints.json")
+}
+
+// loadDepsStates returns the recorded state of every checkout, keyed
+// by directory
+func loadDepsStates() map[string]*depsState {
+	states := make(map[string]*depsState)
+	data, err := os.ReadFile(depsStateFile())
+	if err == nil {
+		json.Unmarshal(data, &states)
+	}
+	return states
+}
+
+func saveDepsState(dir string, state *depsState) error {
+	states := loadDepsStates()
+	states[dir] = state
+	data, err := json.MarshalIndent(states, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(util.StateDir(), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(depsStateFile(), data, 0644)
+}
+
+// validClasspaths lists the classpath files clojure cached for dir
+// whose entries all exist. Files left over from older deps versions
+// may reference jars that have since been cleaned up.
+func validClasspaths(dir string) []string {
+	files, _ := filepath.Glob(filepath.Join(dir, ".cpcache", "*.cp"))
+	var valid []string
+	for _, f := range files {
+		if classpathIntact(f) {
+			valid = append(valid, f)
+		}
+	}
+	return valid
+}
+
+// classpathIntact reports whether a classpath file exists and every
+// jar or directory it references is still on disk
+func classpathIntact(file string) bool {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false
+	}
+	for _, entry := range filepath.SplitList(strings.TrimSpace(string(data))) {
+		// Relative entries are source paths inside the checkout
+		if !filepath.IsAbs(entry) {
+			continue
+		}
+		if _, err := os.Stat(entry); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// staleUnits returns the units whose fingerprint changed since they
+// were last prepared. Every unit is stale when the classpath cache is
+// missing or references deleted jars.
+func staleUnits(dir string, units []depsUnit) []depsUnit {
+	state := loadDepsStates()[dir]
+	if state == nil || len(state.Classpaths) == 0 {
+		return units
+	}
+	for _, f := range state.Classpaths {
+		if !classpathIntact(f) {
+			return units
+		}
+	}
+	var stale []depsUnit
+	for _, u := range units {
+		if state.Units[u.Name] != u.Fingerprint {
+			stale = append(stale, u)
+		}
+	}
+	return stale
+}
+
+// prepareUnits runs each unit's prepare command, recording its
+// fingerprint as soon as it succeeds so a later failure doesn't redo
+// the units that worked
+func prepareUnits(dir string, units []depsUnit) error {
+	state := loadDepsStates()[dir]
+	if state == nil {
+		state = &depsState{}
+	}
+	if state.Units == nil {
+		state.Units = make(map[string]string)
+	}
+
+	for _, u := range units {
+		fmt.Printf("    %s\n", strings.Join(u.Args, " "))
+		cmd := exec.Command(u.Args[0], u.Args[1:]...)
+		cmd.Dir = dir
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s failed: %w", strings.Join(u.Args, " "), err)
+		}
+
+		state.Units[u.Name] = u.Fingerprint
+		state.Classpaths = validClasspaths(dir)
+		if err := saveDepsState(dir, state); err != nil {
+			return fmt.Errorf("failed to record deps fingerprint: %w", err)
+		}
+	}
+	return nil
+}
+
+// configuredAliases reads the extra aliases to prepare from the config
+func configuredAliases() []string {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil
+	}
+	return cfg.Deps.Aliases
+}
@@ -1,39 +1,126 @@
 package setup
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
 )
 
-// DoomSyncStep runs doom sync for Emacs packages
-type DoomSyncStep struct{}
+// DoomSyncStep makes hive-mcp.el available to Emacs. Doom runs doom
+// sync when packages.el or init.el changed since the last sync; vanilla
+// Emacs installs the checkout's elisp with straight.el or package-vc
+// when either is available; Spacemacs installs packages itself on
+// startup.
+type DoomSyncStep struct {
+	HiveMCPDir string
+	Config     *EmacsConfig // defaults to DetectEmacsConfig
+}
+
+func (s *DoomSyncStep) config() EmacsConfig {
+	if s.Config == nil {
+		cfg := DetectEmacsConfig()
+		s.Config = &cfg
+	}
+	return *s.Config
+}
+
+func (s *DoomSyncStep) hiveMCPDir() string {
+	if s.HiveMCPDir != "" {
+		return expandPath(s.HiveMCPDir)
+	}
+	return DefaultHiveMCPDir()
+}
 
 func (s *DoomSyncStep) Name() string {
-	return "Sync Doom Emacs packages"
+	switch s.config().Dist {
+	case DistDoom:
+		return "Sync Doom Emacs packages"
+	case DistVanilla:
+		return "Install hive-mcp.el package"
+	}
+	return "Sync Emacs packages"
 }
 
 func (s *DoomSyncStep) Check() (bool, error) {
-	// Always run doom sync to ensure packages are current
-	return false, nil
+	switch s.config().Dist {
+	case DistDoom:
+		if doomBin() == "" {
+			return false, nil
+		}
+		sum, err := doomFingerprint(s.config().Dir)
+		if err != nil {
+			return false, nil
+		}
+		recorded, _ := os.ReadFile(doomSyncStateFile())
+		return strings.TrimSpace(string(recorded)) == sum, nil
+	case DistVanilla:
+		return s.packageInstalled(), nil
+	}
+	// Spacemacs installs packages itself on startup
+	return true, nil
 }
 
-func (s *DoomSyncStep) Run() error {
-	// Find doom command
+// doomBin returns the doom command, or "" when Doom isn't installed
+func doomBin() string {
 	home, _ := os.UserHomeDir()
 	doomPaths := []string{
 		home + "/.emacs.d/bin/doom",
 		home + "/.config/emacs/bin/doom",
 	}
 
-	var doomCmd string
 	for _, p := range doomPaths {
 		if _, err := os.Stat(p); err == nil {
-			doomCmd = p
-			break
+			return p
 		}
 	}
+	return ""
+}
+
+// DoomInstalled reports whether Doom Emacs is installed
+func DoomInstalled() bool {
+	return doomBin() != ""
+}
+
+// doomSyncStateFile records the fingerprint of the last doom sync
+func doomSyncStateFile() string {
+	return filepath.Join(util.StateDir(), "doom-sync.sha256")
+}
+
+// doomFingerprint hashes the files that decide what doom sync installs
+func doomFingerprint(doomDir string) (string, error) {
+	h := sha256.New()
+	fmt.Fprintln(h, doomDir)
+	for _, name := range []string{"init.el", "packages.el"} {
+		data, err := os.ReadFile(filepath.Join(doomDir, name))
+		if err != nil && !os.IsNotExist(err) {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", name, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (s *DoomSyncStep) Run() error {
+	switch s.config().Dist {
+	case DistDoom:
+		return s.doomSync()
+	case DistVanilla:
+		return s.installPackage()
+	}
+	return nil
+}
 
+func (s *DoomSyncStep) doomSync() error {
+	// Find doom command
+	doomCmd := doomBin()
 	if doomCmd == "" {
 		return fmt.Errorf("doom command not found - is Doom Emacs installed?")
 	}
@@ -46,6 +133,103 @@ func (s *DoomSyncStep) Run() error {
 		return fmt.Errorf("doom sync failed: %w", err)
 	}
 
+	// Record what was synced so unchanged configs are skipped next time
+	if sum, err := doomFingerprint(s.config().Dir); err == nil {
+		if err := os.MkdirAll(util.StateDir(), 0755); err == nil {
+			os.WriteFile(doomSyncStateFile(), []byte(sum+"\n"), 0644)
+		}
+	}
+	return nil
+}
+
+// packageInstalled reports whether hive-mcp.el is installed in vanilla
+// Emacs. A running server is asked with locate-library; otherwise the
+// files are checked, so the user's init file is never loaded just to
+// check.
+func (s *DoomSyncStep) packageInstalled() bool {
+	server := emacs.Server()
+	if emacs.Client(server, "-e", "(emacs-pid)").Run() == nil {
+		out, err := evalElisp(server, "",
+			fmt.Sprintf(`(if (locate-library %s) "t" "nil")`, elispString(hiveMCPFeature)))
+		return err == nil && out == "t"
+	}
+
+	if !util.FileExists(filepath.Join(ElispDir(s.hiveMCPDir()), hiveMCPFeature+".el")) {
+		return false
+	}
+	recorded, _ := os.ReadFile(packageStateFile())
+	switch method := strings.TrimSpace(string(recorded)); method {
+	case "straight":
+		return util.DirExists(filepath.Join(s.userEmacsDir(), "straight", "build", hiveMCPFeature))
+	case "package-vc":
+		matches, _ := filepath.Glob(filepath.Join(s.userEmacsDir(), "elpa", hiveMCPFeature+"*"))
+		return len(matches) > 0
+	case "load-path":
+		// Left to the managed block EmacsConfigStep writes
+		return true
+	}
+	return false
+}
+
+// userEmacsDir is user-emacs-directory, where straight.el and
+// package.el keep installed packages
+func (s *DoomSyncStep) userEmacsDir() string {
+	home, _ := os.UserHomeDir()
+	if dir := s.config().Dir; dir != home {
+		return dir
+	}
+	return filepath.Join(home, ".emacs.d")
+}
+
+// packageStateFile records how hive-mcp.el was installed in vanilla Emacs
+func packageStateFile() string {
+	return filepath.Join(util.StateDir(), "emacs-package")
+}
+
+// installPackage installs the checkout's elisp in vanilla Emacs,
+// preferring straight.el when the config bootstraps it and falling
+// back to package-vc (Emacs 29+). Both link the checkout, so updates
+// to it take effect without reinstalling. With neither (Emacs 28 and
+// no straight.el) there is nothing to install: the managed block from
+// EmacsConfigStep puts the checkout on load-path.
+func (s *DoomSyncStep) installPackage() error {
+	dir := ElispDir(s.hiveMCPDir())
+	if !util.FileExists(filepath.Join(dir, hiveMCPFeature+".el")) {
+		return fmt.Errorf("%s.el not found in %s", hiveMCPFeature, s.hiveMCPDir())
+	}
+	rel, err := filepath.Rel(s.hiveMCPDir(), dir)
+	if err != nil {
+		return err
+	}
+
+	files := elispString(filepath.ToSlash(filepath.Join(rel, "*.el")))
+	form := fmt.Sprintf(`(cond
+  ((fboundp 'straight-use-package)
+   (straight-use-package '(%[1]s :local-repo %[2]s :files (%[3]s)))
+   "straight")
+  ((progn (require 'package)
+          (unless package--initialized (package-initialize))
+          (require 'package-vc nil t)
+          (fboundp 'package-vc-install-from-checkout))
+   (unless (package-installed-p '%[1]s)
+     (package-vc-install-from-checkout %[4]s %[5]s))
+   "package-vc")
+  (t "load-path"))`,
+		hiveMCPFeature, elispString(s.hiveMCPDir()), files, elispString(dir), elispString(hiveMCPFeature))
+
+	method, err := evalElisp(emacs.Server(), s.config().InitFile, form)
+	if err != nil {
+		return fmt.Errorf("failed to install %s.el: %w", hiveMCPFeature, err)
+	}
+	if method == "load-path" {
+		fmt.Printf("    No straight.el or package-vc (Emacs 29+); %s.el is loaded from the checkout\n", hiveMCPFeature)
+	} else {
+		fmt.Printf("    Installed %s.el with %s\n", hiveMCPFeature, method)
+	}
+
+	if err := os.MkdirAll(util.StateDir(), 0755); err == nil {
+		os.WriteFile(packageStateFile(), []byte(method+"\n"), 0644)
+	}
 	return nil
 }
 
@@ -0,0 +1,212 @@
+package setup
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ednTokens splits EDN source into tokens, dropping whitespace, commas,
+// comments and discarded (#_) forms. Joining the tokens with spaces
+// gives a canonical text that ignores formatting changes.
+func ednTokens(src string) ([]string, error) {
+	var toks []string
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case c == ';':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("()[]{}", c) >= 0:
+			toks = append(toks, string(c))
+			i++
+		case c == '"':
+			end, err := ednStringEnd(src, i)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, src[i:end])
+			i = end
+		case c == '#' && i+1 < len(src) && src[i+1] == '{':
+			toks = append(toks, "#{")
+			i += 2
+		case c == '#' && i+1 < len(src) && src[i+1] == '_':
+			toks = append(toks, "#_")
+			i += 2
+		case c == '#' && i+1 < len(src) && src[i+1] == '"':
+			end, err := ednStringEnd(src, i+1)
+			if err != nil {
+				return nil, err
+			}
+			toks = append(toks, src[i:end])
+			i = end
+		case c == '^':
+			// Metadata: its own token so ednFormEnd can attach it to
+			// the form it annotates
+			toks = append(toks, "^")
+			i++
+		case strings.IndexByte("'`~@", c) >= 0:
+			// Quote, syntax quote, unquote(-splicing) and deref prefixes
+			end := i + 1
+			if c == '~' && end < len(src) && src[end] == '@' {
+				end++
+			}
+			toks = append(toks, src[i:end])
+			i = end
+		case c == '\\':
+			// Character literal: the backslash and at least one character
+			end := i + 2
+			for end < len(src) && !ednDelimiter(src[end]) {
+				end++
+			}
+			if end > len(src) {
+				return nil, fmt.Errorf("unterminated character literal")
+			}
+			toks = append(toks, src[i:end])
+			i = end
+		default:
+			end := i + 1
+			for end < len(src) && !ednDelimiter(src[end]) {
+				end++
+			}
+			toks = append(toks, src[i:end])
+			i = end
+		}
+	}
+	return stripDiscards(toks)
+}
+
+func ednStringEnd(src string, start int) (int, error) {
+	for i := start + 1; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1, nil
+		}
+	}
+	return 0, fmt.Errorf("unterminated string")
+}
+
+func ednDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\n\r,;()[]{}\"", c) >= 0
+}
+
+// ednFormEnd returns the index just past the form starting at toks[i]
+func ednFormEnd(toks []string, i int) (int, error) {
+	if i >= len(toks) {
+		return 0, fmt.Errorf("unexpected end of input")
+	}
+	switch t := toks[i]; {
+	case t == "(" || t == "[" || t == "{" || t == "#{":
+		j := i + 1
+		for j < len(toks) && !isCloser(toks[j]) {
+			start := j
+			if toks[j] == "#_" {
+				// A discard needs no form after it in the collection
+				start = j + 1
+			}
+			end, err := ednFormEnd(toks, start)
+			if err != nil {
+				return 0, err
+			}
+			j = end
+		}
+		if j >= len(toks) {
+			return 0, fmt.Errorf("unbalanced %s", t)
+		}
+		return j + 1, nil
+	case isCloser(t):
+		return 0, fmt.Errorf("unexpected %s", t)
+	case t == "^":
+		// Metadata applies to the following form
+		end, err := ednFormEnd(toks, i+1)
+		if err != nil {
+			return 0, err
+		}
+		return ednFormEnd(toks, end)
+	case isPrefix(t):
+		return ednFormEnd(toks, i+1)
+	case t == "#_":
+		// The discarded form is skipped; "#_ #_ a b" discards both
+		end, err := ednFormEnd(toks, i+1)
+		if err != nil {
+			return 0, err
+		}
+		return ednFormEnd(toks, end)
+	case strings.HasPrefix(t, "#") && !strings.HasPrefix(t, `#"`) && !strings.HasPrefix(t, "##"):
+		// Tagged literal: a prefix on the following form
+		return ednFormEnd(toks, i+1)
+	}
+	return i + 1, nil
+}
+
+// isPrefix reports whether t is a reader prefix on the following form
+func isPrefix(t string) bool {
+	switch t {
+	case "'", "`", "~", "~@", "@":
+		return true
+	}
+	return false
+}
+
+func isCloser(t string) bool {
+	return t == ")" || t == "]" || t == "}"
+}
+
+func stripDiscards(toks []string) ([]string, error) {
+	var out []string
+	for i := 0; i < len(toks); {
+		if toks[i] != "#_" {
+			out = append(out, toks[i])
+			i++
+			continue
+		}
+		end, err := ednFormEnd(toks, i+1)
+		if err != nil {
+			return nil, err
+		}
+		i = end
+	}
+	return out, nil
+}
+
+// ednEntry is a key and value of an EDN map, each as canonical text
+type ednEntry struct {
+	Key   string
+	Value string
+}
+
+// ednMapEntries returns the entries of the map making up toks
+func ednMapEntries(toks []string) ([]ednEntry, error) {
+	if len(toks) == 0 || toks[0] != "{" {
+		return nil, fmt.Errorf("expected a map")
+	}
+	end, err := ednFormEnd(toks, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []ednEntry
+	for i := 1; i < end-1; {
+		keyEnd, err := ednFormEnd(toks, i)
+		if err != nil {
+			return nil, err
+		}
+		if keyEnd >= end-1 {
+			return nil, fmt.Errorf("map key %s has no value", strings.Join(toks[i:keyEnd], " "))
+		}
+		valEnd, err := ednFormEnd(toks, keyEnd)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ednEntry{
+			Key:   strings.Join(toks[i:keyEnd], " "),
+			Value: strings.Join(toks[keyEnd:valEnd], " "),
+		})
+		i = valEnd
+	}
+	return entries, nil
+}
@@ -0,0 +1,130 @@
+package setup
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestEDNTokens(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string // tokens joined with spaces
+	}{
+		{"formatting", "{:a  1,\n :b [1 2]}", "{ :a 1 :b [ 1 2 ] }"},
+		{"comment", "{:a 1 ; note\n :b 2}", "{ :a 1 :b 2 }"},
+		{"string with delimiters", `{:a "x ; (y) \"z\""}`, `{ :a "x ; (y) \"z\"" }`},
+		{"regex", `[#"a\"b" :c]`, `[ #"a\"b" :c ]`},
+		{"discard", "{:a #_ :ignored 1}", "{ :a 1 }"},
+		{"discard collection", "{:a #_{:x [1 2]} 1}", "{ :a 1 }"},
+		{"nested discard", "[#_ #_ 1 2 3]", "[ 3 ]"},
+		{"chars", `[\a \( \space \newline]`, `[ \a \( \space \newline ]`},
+		{"set", "#{:a :b}", "#{ :a :b }"},
+		{"tagged", `#inst "2024-01-01"`, `#inst "2024-01-01"`},
+		{"keyword metadata", "^:deps/root {:a 1}", "^ :deps/root { :a 1 }"},
+		{"symbol metadata", "^String x", "^ String x"},
+		{"map metadata", "^{:doc \"d\"} [1]", `^ { :doc "d" } [ 1 ]`},
+		{"quote", "'(a b)", "' ( a b )"},
+		{"unquote splicing", "`(f ~@xs ~y @z)", "` ( f ~@ xs ~ y @ z )"},
+		{"symbol with quote", "[foo' bar]", "[ foo' bar ]"},
+		{"nesting", "{:a {:b [{:c #{1}}]}}", "{ :a { :b [ { :c #{ 1 } } ] } }"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, err := ednTokens(tt.src)
+			if err != nil {
+				t.Fatalf("ednTokens(%q): %v", tt.src, err)
+			}
+			if got := strings.Join(toks, " "); got != tt.want {
+				t.Errorf("ednTokens(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEDNTokensErrors(t *testing.T) {
+	for _, src := range []string{`{:a "open`, `[#"open]`, "[#_]"} {
+		if toks, err := ednTokens(src); err == nil {
+			t.Errorf("ednTokens(%q) = %q, want an error", src, toks)
+		}
+	}
+}
+
+func TestEDNMapEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []ednEntry
+	}{
+		{
+			name: "plain",
+			src:  "{:paths [\"src\"] :deps {a/b {:mvn/version \"1\"}}}",
+			want: []ednEntry{
+				{":paths", `[ "src" ]`},
+				{":deps", `{ a/b { :mvn/version "1" } }`},
+			},
+		},
+		{
+			name: "metadata stays with its value",
+			src:  "{:deps ^:deps/root {a/b {:local/root \".\"}} :aliases {:dev {}}}",
+			want: []ednEntry{
+				{":deps", `^ :deps/root { a/b { :local/root "." } }`},
+				{":aliases", "{ :dev { } }"},
+			},
+		},
+		{
+			name: "metadata map on a key",
+			src:  "{^{:x 1} k v :b 2}",
+			want: []ednEntry{
+				{"^ { :x 1 } k", "v"},
+				{":b", "2"},
+			},
+		},
+		{
+			name: "discarded entry",
+			src:  "{:a 1 #_#_ :b 2 :c 3}",
+			want: []ednEntry{{":a", "1"}, {":c", "3"}},
+		},
+		{
+			name: "quoted task code",
+			src:  "{:tasks {build (shell 'x)} :min-bb-version \"1.0\"}",
+			want: []ednEntry{
+				{":tasks", "{ build ( shell ' x ) }"},
+				{":min-bb-version", `"1.0"`},
+			},
+		},
+		{
+			name: "tagged value",
+			src:  `{:at #inst "2024-01-01" :n 1}`,
+			want: []ednEntry{{":at", `#inst "2024-01-01"`}, {":n", "1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			toks, err := ednTokens(tt.src)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ednMapEntries(toks)
+			if err != nil {
+				t.Fatalf("ednMapEntries: %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ednMapEntries(%q) = %q, want %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEDNMapEntriesErrors(t *testing.T) {
+	for _, src := range []string{"[1 2]", "{:a}", "{:a 1", "{:a ]}"} {
+		toks, err := ednTokens(src)
+		if err != nil {
+			continue
+		}
+		if got, err := ednMapEntries(toks); err == nil {
+			t.Errorf("ednMapEntries(%q) = %q, want an error", src, got)
+		}
+	}
+}
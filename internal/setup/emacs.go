@@ -4,19 +4,53 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
 	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
 )
 
+// DefaultEmacsReadyTimeout bounds how long Run waits for the daemon to
+// load hive-mcp; Doom configs routinely take 10+ seconds
+const DefaultEmacsReadyTimeout = 60 * time.Second
+
+// hiveMCPFeature is the elisp feature provided by hive-mcp.el
+const hiveMCPFeature = "hive-mcp"
+
 // EmacsDaemonStep starts the Emacs daemon
-type EmacsDaemonStep struct{}
+type EmacsDaemonStep struct {
+	ServerName   string        // server name or socket path, defaults to the configured server
+	ReadyTimeout time.Duration // defaults to DefaultEmacsReadyTimeout
+}
 
 func (s *EmacsDaemonStep) Name() string {
 	return "Start Emacs daemon"
 }
 
+func (s *EmacsDaemonStep) server() string {
+	if s.ServerName != "" {
+		return s.ServerName
+	}
+	return emacs.Server()
+}
+
+func (s *EmacsDaemonStep) readyTimeout() time.Duration {
+	if s.ReadyTimeout > 0 {
+		return s.ReadyTimeout
+	}
+	return DefaultEmacsReadyTimeout
+}
+
+// logFile captures the daemon's startup output
+func (s *EmacsDaemonStep) logFile() string {
+	return filepath.Join(util.StateDir(), "emacs-daemon.log")
+}
+
 func (s *EmacsDaemonStep) Check() (bool, error) {
 	// Check if Emacs daemon is running
-	cmd := exec.Command("emacsclient", "-e", "(emacs-pid)")
+	cmd := emacs.Client(s.server(), "-e", "(emacs-pid)")
 	if err := cmd.Run(); err == nil {
 		return true, nil
 	}
@@ -24,29 +58,137 @@ func (s *EmacsDaemonStep) Check() (bool, error) {
 }
 
 func (s *EmacsDaemonStep) Run() error {
-	// Start Emacs daemon
-	cmd := exec.Command("emacs", "--daemon")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	if err := os.MkdirAll(util.StateDir(), 0755); err != nil {
+		return fmt.Errorf("failed to create state directory: %w", err)
+	}
+	logFile, err := os.Create(s.logFile())
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", s.logFile(), err)
+	}
+	defer logFile.Close()
 
-	if err := cmd.Run(); err != nil {
+	// Start Emacs daemon, keeping its output for diagnostics
+	cmd := exec.Command("emacs", emacs.DaemonFlag(s.server()))
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	if err := cmd.Start(); err != nil {
 		return fmt.Errorf("failed to start Emacs daemon: %w", err)
 	}
 
-	// Wait briefly for daemon to initialize
-	time.Sleep(2 * time.Second)
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
 
-	// Verify it's running
-	done, _ := s.Check()
-	if !done {
-		return fmt.Errorf("Emacs daemon started but not responding")
+	if err := s.waitReady(exited); err != nil {
+		return fmt.Errorf("%w%s", err, s.startupOutput())
 	}
-
 	return nil
 }
 
+// daemonState is the readiness of the Emacs daemon
+type daemonState int
+
+const (
+	daemonDown daemonState = iota
+	daemonUp               // server answers but hive-mcp isn't loaded
+	daemonReady
+)
+
+// emacsReadiness asks the daemon whether hive-mcp has been loaded
+func emacsReadiness(server string) daemonState {
+	out, err := emacs.Client(server, "-e", fmt.Sprintf("(featurep '%s)", hiveMCPFeature)).Output()
+	if err != nil {
+		return daemonDown
+	}
+	if strings.TrimSpace(string(out)) == "t" {
+		return daemonReady
+	}
+	return daemonUp
+}
+
+// WaitReady waits until a daemon started elsewhere (e.g. by a systemd
+// unit) answers and has loaded hive-mcp, as Run does
+func (s *EmacsDaemonStep) WaitReady() error {
+	return s.waitReady(nil)
+}
+
+// waitReady polls emacsclient with backoff until hive-mcp is loaded,
+// the daemon process fails, or the deadline passes
+func (s *EmacsDaemonStep) waitReady(exited <-chan error) error {
+	timeout := s.readyTimeout()
+	deadline := time.Now().Add(timeout)
+	delay := 250 * time.Millisecond
+
+	for {
+		select {
+		case err := <-exited:
+			if err != nil {
+				return fmt.Errorf("emacs %s failed: %w", emacs.DaemonFlag(s.server()), err)
+			}
+			exited = nil // parent exited cleanly after forking the server
+		default:
+		}
+
+		state := emacsReadiness(s.server())
+		if state == daemonReady {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			if state == daemonUp {
+				return fmt.Errorf("Emacs %s is running but %s was not loaded within %s - ensure hive-mcp.el is required in your config", emacs.DisplayName(s.server()), hiveMCPFeature, timeout)
+			}
+			return fmt.Errorf("Emacs %s not responding after %s", emacs.DisplayName(s.server()), timeout)
+		}
+
+		time.Sleep(delay)
+		if delay < 2*time.Second {
+			delay *= 2
+		}
+	}
+}
+
+// startupOutput formats the tail of the daemon's startup log
+func (s *EmacsDaemonStep) startupOutput() string {
+	lines := tailFile(s.logFile(), 20)
+	if len(lines) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n    Last daemon output (%s):\n      %s", s.logFile(), strings.Join(lines, "\n      "))
+}
+
+// tailFile returns the last n non-empty lines of a file
+func tailFile(path string, n int) []string {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return lines
+}
+
+// Rollback stops the daemon and waits for it to go away. emacsclient
+// can fail when the server drops the connection while exiting, so
+// whether the daemon still answers decides the result.
 func (s *EmacsDaemonStep) Rollback() error {
-	// Kill emacs daemon
-	exec.Command("emacsclient", "-e", "(kill-emacs)").Run()
-	return nil
+	emacs.Client(s.server(), "-e", "(kill-emacs)").Run()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if running, _ := s.Check(); !running {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("Emacs %s still running after kill-emacs", emacs.DisplayName(s.server()))
+		}
+		time.Sleep(250 * time.Millisecond)
+	}
 }
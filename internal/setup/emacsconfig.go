@@ -0,0 +1,215 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// EmacsDist is the Emacs configuration framework in use
+type EmacsDist string
+
+const (
+	DistDoom      EmacsDist = "doom"
+	DistSpacemacs EmacsDist = "spacemacs"
+	DistVanilla   EmacsDist = "vanilla"
+)
+
+// EmacsConfig describes the user's Emacs configuration
+type EmacsConfig struct {
+	Dist     EmacsDist
+	Dir      string // private config directory ($DOOMDIR for Doom)
+	InitFile string // file Emacs (or Spacemacs) loads the user config from
+}
+
+// DetectEmacsConfig finds the user's Emacs configuration. Doom is
+// recognized by $DOOMDIR, ~/.config/doom or ~/.doom.d, Spacemacs by its
+// dotfile; anything else is treated as vanilla Emacs.
+func DetectEmacsConfig() EmacsConfig {
+	home, _ := os.UserHomeDir()
+
+	doomDirs := []string{
+		os.Getenv("DOOMDIR"),
+		filepath.Join(home, ".config", "doom"),
+		filepath.Join(home, ".doom.d"),
+	}
+	for _, dir := range doomDirs {
+		dir = util.ExpandPath(dir)
+		if dir != "" && util.DirExists(dir) {
+			return EmacsConfig{Dist: DistDoom, Dir: dir, InitFile: filepath.Join(dir, "init.el")}
+		}
+	}
+
+	if util.FileExists(filepath.Join(home, ".spacemacs")) {
+		return EmacsConfig{Dist: DistSpacemacs, Dir: home, InitFile: filepath.Join(home, ".spacemacs")}
+	}
+	if init := filepath.Join(home, ".spacemacs.d", "init.el"); util.FileExists(init) {
+		return EmacsConfig{Dist: DistSpacemacs, Dir: filepath.Dir(init), InitFile: init}
+	}
+
+	// Emacs' own lookup order; ~/.config/emacs is only used when
+	// ~/.emacs.d doesn't exist
+	for _, name := range []string{".emacs", ".emacs.el"} {
+		if init := filepath.Join(home, name); util.FileExists(init) {
+			return EmacsConfig{Dist: DistVanilla, Dir: home, InitFile: init}
+		}
+	}
+	dir := filepath.Join(home, ".emacs.d")
+	if !util.DirExists(dir) && util.DirExists(filepath.Join(home, ".config", "emacs")) {
+		dir = filepath.Join(home, ".config", "emacs")
+	}
+	return EmacsConfig{Dist: DistVanilla, Dir: dir, InitFile: filepath.Join(dir, "init.el")}
+}
+
+// ElispDir returns the directory of the hive-mcp checkout holding
+// hive-mcp.el
+func ElispDir(hiveMCPDir string) string {
+	for _, sub := range []string{"elisp", "emacs", "lisp", ""} {
+		dir := filepath.Join(hiveMCPDir, sub)
+		if util.FileExists(filepath.Join(dir, hiveMCPFeature+".el")) {
+			return dir
+		}
+	}
+	return filepath.Join(hiveMCPDir, "elisp")
+}
+
+// evalElisp evaluates form in the running Emacs server, or in a batch
+// Emacs that loads initFile when no server answers, and returns the
+// printed result
+func evalElisp(server, initFile, form string) (string, error) {
+	if emacs.Client(server, "-e", "(emacs-pid)").Run() == nil {
+		out, err := emacs.Client(server, "-e", form).CombinedOutput()
+		result := strings.TrimSpace(string(out))
+		if err != nil {
+			return "", fmt.Errorf("emacsclient: %s", result)
+		}
+		if unquoted, err := strconv.Unquote(result); err == nil {
+			result = unquoted
+		}
+		return result, nil
+	}
+
+	args := []string{"--batch"}
+	if util.FileExists(initFile) {
+		args = append(args, "-l", initFile)
+	}
+	args = append(args, "--eval", fmt.Sprintf(`(princ (format "\n%%s" %s))`, form))
+	out, err := exec.Command("emacs", args...).Output()
+	if err != nil {
+		if exit, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("emacs --batch: %s", lastLine(string(exit.Stderr)))
+		}
+		return "", err
+	}
+	// The init file may print on its own; the result is the last line
+	return lastLine(string(out)), nil
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return lines[len(lines)-1]
+}
+
+// elispString quotes s as an elisp string literal
+func elispString(s string) string {
+	return strconv.Quote(s)
+}
+
+// EmacsConfigStep writes a managed block into the user's Emacs config
+// that puts hive-mcp's elisp on the load-path and requires it: Doom's
+// config.el, the Spacemacs dotfile or the vanilla init file
+type EmacsConfigStep struct {
+	HiveMCPDir string
+	Config     *EmacsConfig // defaults to DetectEmacsConfig
+}
+
+func (s *EmacsConfigStep) Name() string {
+	return "Configure Emacs to load hive-mcp"
+}
+
+func (s *EmacsConfigStep) config() EmacsConfig {
+	if s.Config == nil {
+		cfg := DetectEmacsConfig()
+		s.Config = &cfg
+	}
+	return *s.Config
+}
+
+func (s *EmacsConfigStep) hiveMCPDir() string {
+	if s.HiveMCPDir != "" {
+		return expandPath(s.HiveMCPDir)
+	}
+	return DefaultHiveMCPDir()
+}
+
+// File returns the config file holding the managed block
+func (s *EmacsConfigStep) File() string {
+	cfg := s.config()
+	if cfg.Dist == DistDoom {
+		return filepath.Join(cfg.Dir, "config.el")
+	}
+	return cfg.InitFile
+}
+
+// block renders the managed elisp. HIVE_MCP_DIR wins when Emacs
+// inherits it from the shell, so moving the checkout only needs the
+// shell block updated.
+func (s *EmacsConfigStep) block() []string {
+	dir := s.hiveMCPDir()
+	sub, err := filepath.Rel(dir, ElispDir(dir))
+	if err != nil {
+		sub = "elisp"
+	}
+
+	body := []string{
+		fmt.Sprintf(`(let ((dir (expand-file-name %s (or (getenv "HIVE_MCP_DIR") %s))))`,
+			elispString(filepath.ToSlash(sub)), elispString(dir)),
+		`  (when (file-directory-p dir)`,
+		`    (add-to-list 'load-path dir)`,
+		`    (with-demoted-errors "hive-mcp: %S"`,
+		fmt.Sprintf(`      (require '%s))))`, hiveMCPFeature),
+	}
+	if s.config().Dist == DistSpacemacs {
+		// The dotfile is read before layers load their packages
+		body[0] = "(add-hook 'emacs-startup-hook (lambda () " + body[0]
+		body[len(body)-1] += "))"
+	}
+	return body
+}
+
+func (s *EmacsConfigStep) Check() (bool, error) {
+	content, err := os.ReadFile(s.File())
+	if err != nil {
+		return false, nil
+	}
+	current, ok := markedSection(string(content), ";;")
+	return ok && slices.Equal(current, s.block()), nil
+}
+
+func (s *EmacsConfigStep) Run() error {
+	return writeMarkedSection(s.File(), ";;", s.block())
+}
+
+// HasBlock reports whether the config file contains the managed block
+func (s *EmacsConfigStep) HasBlock() bool {
+	content, err := os.ReadFile(s.File())
+	if err != nil {
+		return false
+	}
+	_, ok := markedSection(string(content), ";;")
+	return ok
+}
+
+func (s *EmacsConfigStep) Rollback() error {
+	if !s.HasBlock() {
+		return nil
+	}
+	return removeMarkedSection(s.File(), ";;")
+}
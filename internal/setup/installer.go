@@ -0,0 +1,157 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+)
+
+// Installer installs system packages through a package manager
+type Installer interface {
+	Name() string
+	Install(pkgs ...string) error
+	Upgrade(pkgs ...string) error
+	NeedsRoot() bool
+}
+
+// packageNames maps each prerequisite tool to its package name per
+// package manager. Tools missing for a manager are installed from the
+// pinned release tarballs instead.
+var packageNames = map[string]map[string]string{
+	"git": {
+		"apt": "git", "dnf": "git", "yum": "git", "pacman": "git",
+		"zypper": "git", "apk": "git", "brew": "git",
+	},
+	"java": {
+		"apt": "openjdk-17-jdk", "dnf": "java-17-openjdk-devel", "yum": "java-17-openjdk-devel",
+		"pacman": "jdk17-openjdk", "zypper": "java-17-openjdk-devel", "apk": "openjdk17",
+		"brew": "openjdk@17",
+	},
+	"docker": {
+		// Fedora packages the Docker engine as moby-engine
+		"apt": "docker.io", "dnf": "moby-engine", "yum": "docker",
+		"pacman": "docker", "zypper": "docker", "apk": "docker", "brew": "docker",
+	},
+	"emacs": {
+		"apt": "emacs", "dnf": "emacs", "yum": "emacs", "pacman": "emacs",
+		"zypper": "emacs", "apk": "emacs", "brew": "emacs-plus@29",
+	},
+	"clojure": {
+		"pacman": "clojure", "brew": "clojure/tools/clojure",
+	},
+	"bb": {
+		"brew": "borkdude/brew/babashka",
+	},
+}
+
+// versionedPackages are tools whose package names pin a major version,
+// so an outdated install is fixed by installing the pinned package
+// alongside it rather than upgrading
+var versionedPackages = map[string]bool{"java": true}
+
+// PackageName returns the package providing tool for a package manager
+func PackageName(manager, tool string) (string, bool) {
+	pkg, ok := packageNames[tool][manager]
+	return pkg, ok
+}
+
+// packageManager is an Installer backed by a package manager CLI
+type packageManager struct {
+	name    string
+	sudo    bool
+	install []string // install subcommand and non-interactive flags
+	upgrade []string // upgrade subcommand for already installed packages
+}
+
+var packageManagers = map[string]packageManager{
+	"apt": {name: "apt", sudo: true,
+		install: []string{"apt-get", "install", "-y"},
+		upgrade: []string{"apt-get", "install", "-y", "--only-upgrade"}},
+	"dnf": {name: "dnf", sudo: true,
+		install: []string{"dnf", "install", "-y"},
+		upgrade: []string{"dnf", "upgrade", "-y"}},
+	"yum": {name: "yum", sudo: true,
+		install: []string{"yum", "install", "-y"},
+		upgrade: []string{"yum", "update", "-y"}},
+	"pacman": {name: "pacman", sudo: true,
+		install: []string{"pacman", "-S", "--needed", "--noconfirm"},
+		upgrade: []string{"pacman", "-S", "--noconfirm"}},
+	"zypper": {name: "zypper", sudo: true,
+		install: []string{"zypper", "--non-interactive", "install"},
+		upgrade: []string{"zypper", "--non-interactive", "update"}},
+	"apk": {name: "apk", sudo: true,
+		install: []string{"apk", "add"},
+		upgrade: []string{"apk", "add", "--upgrade"}},
+	"brew": {name: "brew", sudo: false,
+		install: []string{"brew", "install"},
+		upgrade: []string{"brew", "upgrade"}},
+}
+
+func (m packageManager) Name() string {
+	return m.name
+}
+
+func (m packageManager) NeedsRoot() bool {
+	return m.sudo
+}
+
+// command builds the command line running subcommand for pkgs
+func (m packageManager) command(subcommand []string, pkgs ...string) []string {
+	args := append(append([]string{}, subcommand...), pkgs...)
+	if m.sudo && os.Geteuid() != 0 {
+		args = append([]string{"sudo"}, args...)
+	}
+	return args
+}
+
+func (m packageManager) run(verb string, subcommand []string, pkgs ...string) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+
+	args := m.command(subcommand, pkgs...)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s %s failed: %w", m.name, verb, err)
+	}
+	return nil
+}
+
+func (m packageManager) Install(pkgs ...string) error {
+	return m.run("install", m.install, pkgs...)
+}
+
+func (m packageManager) Upgrade(pkgs ...string) error {
+	return m.run("upgrade", m.upgrade, pkgs...)
+}
+
+// SupportedPackageManagers lists the package managers with an installer
+func SupportedPackageManagers() []string {
+	names := make([]string, 0, len(packageManagers))
+	for name := range packageManagers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewInstaller returns the installer for the detected platform
+func NewInstaller(platform detect.PlatformInfo) (Installer, error) {
+	pm, ok := packageManagers[platform.PackageManager]
+	if !ok {
+		return nil, fmt.Errorf("unsupported package manager %q on %s %s (supported: %v)",
+			platform.PackageManager, platform.OS, platform.Distro, SupportedPackageManagers())
+	}
+	if _, err := exec.LookPath(pm.install[0]); err != nil {
+		if pm.name == "brew" {
+			return nil, fmt.Errorf("Homebrew not found - please install from https://brew.sh")
+		}
+		return nil, fmt.Errorf("%s not found on PATH", pm.install[0])
+	}
+	return pm, nil
+}
@@ -0,0 +1,136 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+)
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		manager, tool string
+		want          string
+		ok            bool
+	}{
+		{"apt", "git", "git", true},
+		{"apt", "java", "openjdk-17-jdk", true},
+		{"apt", "docker", "docker.io", true},
+		{"apt", "emacs", "emacs", true},
+		{"apt", "clojure", "", false},
+		{"apt", "bb", "", false},
+		{"dnf", "java", "java-17-openjdk-devel", true},
+		{"dnf", "docker", "moby-engine", true},
+		{"dnf", "clojure", "", false},
+		{"yum", "java", "java-17-openjdk-devel", true},
+		{"yum", "docker", "docker", true},
+		{"pacman", "java", "jdk17-openjdk", true},
+		{"pacman", "clojure", "clojure", true},
+		{"pacman", "bb", "", false},
+		{"zypper", "java", "java-17-openjdk-devel", true},
+		{"zypper", "emacs", "emacs", true},
+		{"apk", "java", "openjdk17", true},
+		{"apk", "docker", "docker", true},
+		{"brew", "java", "openjdk@17", true},
+		{"brew", "emacs", "emacs-plus@29", true},
+		{"brew", "clojure", "clojure/tools/clojure", true},
+		{"brew", "bb", "borkdude/brew/babashka", true},
+		{"port", "git", "", false},
+		{"apt", "unknown", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.manager+"/"+tt.tool, func(t *testing.T) {
+			got, ok := PackageName(tt.manager, tt.tool)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("PackageName(%q, %q) = %q, %v; want %q, %v",
+					tt.manager, tt.tool, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+// Every package manager must name git, java, docker and emacs
+func TestPackageNamesCoverSystemTools(t *testing.T) {
+	for _, manager := range SupportedPackageManagers() {
+		for _, tool := range []string{"git", "java", "docker", "emacs"} {
+			if _, ok := PackageName(manager, tool); !ok {
+				t.Errorf("no %s package for %s", tool, manager)
+			}
+		}
+	}
+}
+
+func TestNewInstaller(t *testing.T) {
+	tests := []struct {
+		manager  string
+		bin      string
+		root     bool
+		install  string
+		upgrade  string
+		notFound string
+	}{
+		{"apt", "apt-get", true, "apt-get install -y git", "apt-get install -y --only-upgrade git", "apt-get not found on PATH"},
+		{"dnf", "dnf", true, "dnf install -y git", "dnf upgrade -y git", "dnf not found on PATH"},
+		{"yum", "yum", true, "yum install -y git", "yum update -y git", "yum not found on PATH"},
+		{"pacman", "pacman", true, "pacman -S --needed --noconfirm git", "pacman -S --noconfirm git", "pacman not found on PATH"},
+		{"zypper", "zypper", true, "zypper --non-interactive install git", "zypper --non-interactive update git", "zypper not found on PATH"},
+		{"apk", "apk", true, "apk add git", "apk add --upgrade git", "apk not found on PATH"},
+		{"brew", "brew", false, "brew install git", "brew upgrade git", "Homebrew not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.manager, func(t *testing.T) {
+			platform := detect.PlatformInfo{OS: "linux", PackageManager: tt.manager}
+
+			t.Setenv("PATH", t.TempDir())
+			if _, err := NewInstaller(platform); err == nil || !strings.Contains(err.Error(), tt.notFound) {
+				t.Fatalf("NewInstaller without %s: got %v, want %q", tt.bin, err, tt.notFound)
+			}
+
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, tt.bin), []byte("#!/bin/sh\n"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("PATH", dir)
+
+			inst, err := NewInstaller(platform)
+			if err != nil {
+				t.Fatalf("NewInstaller: %v", err)
+			}
+			if inst.Name() != tt.manager {
+				t.Errorf("Name() = %q, want %q", inst.Name(), tt.manager)
+			}
+			if inst.NeedsRoot() != tt.root {
+				t.Errorf("NeedsRoot() = %v, want %v", inst.NeedsRoot(), tt.root)
+			}
+
+			pm := inst.(packageManager)
+			prefix := ""
+			if tt.root && os.Geteuid() != 0 {
+				prefix = "sudo "
+			}
+			if got := strings.Join(pm.command(pm.install, "git"), " "); got != prefix+tt.install {
+				t.Errorf("install command = %q, want %q", got, prefix+tt.install)
+			}
+			if got := strings.Join(pm.command(pm.upgrade, "git"), " "); got != prefix+tt.upgrade {
+				t.Errorf("upgrade command = %q, want %q", got, prefix+tt.upgrade)
+			}
+		})
+	}
+}
+
+func TestNewInstallerUnsupported(t *testing.T) {
+	_, err := NewInstaller(detect.PlatformInfo{OS: "linux", Distro: "gentoo", PackageManager: "emerge"})
+	if err == nil || !strings.Contains(err.Error(), `unsupported package manager "emerge"`) {
+		t.Fatalf("got %v, want unsupported package manager error", err)
+	}
+}
+
+func TestSupportedPackageManagers(t *testing.T) {
+	want := []string{"apk", "apt", "brew", "dnf", "pacman", "yum", "zypper"}
+	if got := SupportedPackageManagers(); !slices.Equal(got, want) {
+		t.Errorf("SupportedPackageManagers() = %v, want %v", got, want)
+	}
+}
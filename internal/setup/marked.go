@@ -0,0 +1,122 @@
+package setup
+
+import "strings"
+
+// managedMarker tags the blocks hive-mcp-cli writes into user config
+// files. Each file type prefixes it with its own comment syntax.
+const managedMarker = "hive-mcp-cli managed"
+
+// markerLines returns the START and END marker lines for a file whose
+// line comments start with comment
+func markerLines(comment string) (string, string) {
+	return comment + " " + managedMarker + " - START", comment + " " + managedMarker + " - END"
+}
+
+// markedSection returns the lines between the managed START and END
+// markers in content
+func markedSection(content, comment string) ([]string, bool) {
+	start, end := markerLines(comment)
+	var body []string
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		switch {
+		case strings.TrimSpace(line) == start:
+			inSection = true
+		case strings.TrimSpace(line) == end && inSection:
+			return body, true
+		case inSection:
+			body = append(body, line)
+		}
+	}
+	return nil, false
+}
+
+// replaceMarkedSection returns content with the managed section's body
+// replaced, or with a new section appended when there is none. comment
+// is the line comment prefix of the file's language.
+func replaceMarkedSection(content, comment string, body []string) string {
+	startLine, endLine := markerLines(comment)
+	block := append([]string{startLine}, body...)
+	block = append(block, endLine)
+
+	lines, newline := splitLines(content)
+	start, end := findSection(lines, startLine, endLine)
+
+	var out []string
+	switch {
+	case start >= 0:
+		out = append(out, lines[:start]...)
+		out = append(out, block...)
+		out = append(out, lines[end+1:]...)
+	case len(lines) == 0:
+		out = block
+		newline = true
+	default:
+		out = append(lines, "")
+		out = append(out, block...)
+		newline = true
+	}
+	return joinLines(out, newline)
+}
+
+// stripMarkedSection returns content without the managed section and
+// the blank line written before it
+func stripMarkedSection(content, comment string) string {
+	startLine, endLine := markerLines(comment)
+	lines, newline := splitLines(content)
+	start, end := findSection(lines, startLine, endLine)
+	if start < 0 {
+		return content
+	}
+	if start > 0 && strings.TrimSpace(lines[start-1]) == "" {
+		start--
+	}
+	return joinLines(append(lines[:start:start], lines[end+1:]...), newline)
+}
+
+// findSection returns the line indexes of the START and END markers,
+// or -1 when the file has no complete section
+func findSection(lines []string, startLine, endLine string) (int, int) {
+	start := -1
+	for i, line := range lines {
+		switch {
+		case start < 0 && strings.TrimSpace(line) == startLine:
+			start = i
+		case start >= 0 && strings.TrimSpace(line) == endLine:
+			return start, i
+		}
+	}
+	return -1, -1
+}
+
+// splitLines splits content into lines and reports whether it ended
+// with a newline, so edits can keep that as it was
+func splitLines(content string) ([]string, bool) {
+	if content == "" {
+		return nil, false
+	}
+	newline := strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), newline
+}
+
+func joinLines(lines []string, newline bool) string {
+	content := strings.Join(lines, "\n")
+	if newline && len(lines) > 0 {
+		content += "\n"
+	}
+	return content
+}
+
+// writeMarkedSection creates or updates the managed section of a file
+func writeMarkedSection(path, comment string, body []string) error {
+	return editFile(path, func(content string) string {
+		return replaceMarkedSection(content, comment, body)
+	})
+}
+
+// removeMarkedSection removes the hive-mcp-cli managed section from a file
+func removeMarkedSection(path, comment string) error {
+	return editFile(path, func(content string) string {
+		return stripMarkedSection(content, comment)
+	})
+}
@@ -4,12 +4,19 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 )
 
 // MCPStep registers the hive-mcp server with Claude CLI
 type MCPStep struct {
 	HiveMCPDir string
+
+	// Secrets launches the server through 'hive secrets exec' so stored
+	// API keys are injected without being written to Claude's config.
+	// It is off for passphrase-protected stores: Claude starts the
+	// server without a terminal to prompt on.
+	Secrets bool
 }
 
 func (s *MCPStep) Name() string {
@@ -32,7 +39,28 @@ func (s *MCPStep) Check() (bool, error) {
 		return false, nil
 	}
 
-	return strings.Contains(string(output), "emacs"), nil
+	if !s.Secrets || hiveCommand() == "" {
+		return strings.Contains(string(output), "emacs"), nil
+	}
+	// Registered, and through the secrets wrapper
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.HasPrefix(line, "emacs:") {
+			return strings.Contains(line, "secrets exec"), nil
+		}
+	}
+	return false, nil
+}
+
+// hiveCommand returns the path of the hive CLI, or "" when it can't be
+// found (e.g. when running as hive-setup-mcp without hive on PATH)
+func hiveCommand() string {
+	if exe, err := os.Executable(); err == nil && filepath.Base(exe) == "hive" {
+		return exe
+	}
+	if path, err := exec.LookPath("hive"); err == nil {
+		return path
+	}
+	return ""
 }
 
 func (s *MCPStep) Run() error {
@@ -45,10 +73,20 @@ func (s *MCPStep) Run() error {
 
 	// Register the MCP server
 	// claude mcp add emacs -- bb --prn -cp <hive-mcp>/bb.edn -m bb.hive-mcp.server/-main
-	cmd := exec.Command("claude", "mcp", "add", "emacs", "--",
-		"bb", "--prn",
-		"-cp", hiveMCP+"/bb.edn",
-		"-m", "bb.hive-mcp.server/-main")
+	server := []string{"bb", "--prn",
+		"-cp", hiveMCP + "/bb.edn",
+		"-m", "bb.hive-mcp.server/-main"}
+	if s.Secrets {
+		if hive := hiveCommand(); hive != "" {
+			server = append([]string{hive, "secrets", "exec", "--"}, server...)
+			// Replace a registration made without the wrapper
+			exec.Command("claude", "mcp", "remove", "emacs").Run()
+		} else {
+			fmt.Println("    hive not found on PATH; registering without secret injection")
+		}
+	}
+
+	cmd := exec.Command("claude", append([]string{"mcp", "add", "emacs", "--"}, server...)...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -9,7 +9,11 @@ import (
 )
 
 // OllamaStep ensures Ollama is running with the required model
-type OllamaStep struct{}
+type OllamaStep struct {
+	// Offline skips the pull; the model must already be in Ollama's
+	// model store (e.g. imported from an offline bundle)
+	Offline bool
+}
 
 func (s *OllamaStep) Name() string {
 	return "Setup Ollama with nomic-embed-text model"
@@ -35,6 +39,13 @@ func (s *OllamaStep) Run() error {
 		return fmt.Errorf("ollama not installed - please install from https://ollama.ai")
 	}
 
+	if s.Offline {
+		if err := exec.Command("ollama", "show", "nomic-embed-text").Run(); err != nil {
+			return fmt.Errorf("nomic-embed-text not available offline - is it in the bundle?")
+		}
+		return nil
+	}
+
 	// Pull the embedding model
 	cmd := exec.Command("ollama", "pull", "nomic-embed-text")
 	cmd.Stdout = os.Stdout
@@ -1,25 +1,126 @@
 package setup
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
 )
 
 // PrerequisitesStep installs system prerequisites
 type PrerequisitesStep struct {
-	Platform string // "linux" or "darwin"
+	Platform detect.PlatformInfo // selects the package manager backend
+
+	// UserMode installs into ~/.local without sudo. Tools that need a
+	// system install are collected in AdminItems instead.
+	UserMode   bool
+	AdminItems []string
+
+	// VersionManager installs Java, Clojure and Babashka through mise,
+	// asdf or sdkman ("auto" picks the first one detected) instead of
+	// the system package manager
+	VersionManager string
+
+	// Offline installs Clojure, Babashka and a JDK from pinned release
+	// tarballs (served from an offline bundle) instead of the network
+	Offline bool
+}
+
+// prerequisiteTools are the binaries hive-mcp needs on PATH
+var prerequisiteTools = []string{"git", "java", "clojure", "bb", "docker", "emacs"}
+
+// ToolState is the outcome of checking a tool against its minimum version
+type ToolState int
+
+const (
+	ToolOK ToolState = iota
+	ToolMissing
+	ToolOutdated
+)
+
+func (s ToolState) String() string {
+	switch s {
+	case ToolOK:
+		return "ok"
+	case ToolMissing:
+		return "missing"
+	case ToolOutdated:
+		return "too old"
+	default:
+		return "unknown"
+	}
+}
+
+// ToolPlan describes what the step will do for one tool
+type ToolPlan struct {
+	Tool     string // binary name
+	Name     string // display name
+	Version  string // detected version, "" when missing
+	Required string // minimum version
+	State    ToolState
+	Manager  string // version manager whose shim is on PATH, if any
+}
+
+// ToolOutcome reports the result of installing or upgrading one tool
+type ToolOutcome struct {
+	Tool  string
+	State ToolState // state before the step acted
+	Err   error
 }
 
 func (s *PrerequisitesStep) Name() string {
 	return "Install system prerequisites"
 }
 
+// Plan checks every tool against the minimum versions from the
+// detect prerequisite specs
+func (s *PrerequisitesStep) Plan() []ToolPlan {
+	if s.UserMode {
+		// Tools installed by an earlier --user run live in ~/.local/bin
+		ensureUserBinOnPath()
+	}
+
+	plan := make([]ToolPlan, 0, len(prerequisiteTools))
+	for _, tool := range prerequisiteTools {
+		entry := ToolPlan{Tool: tool, Name: tool, Manager: shimOwner(tool)}
+
+		check, ok := detect.CheckPrereq(tool)
+		if !ok {
+			// No version spec; presence is all we can verify
+			if _, err := exec.LookPath(tool); err != nil {
+				entry.State = ToolMissing
+			}
+			plan = append(plan, entry)
+			continue
+		}
+
+		entry.Name = check.Name
+		entry.Version = check.Version
+		entry.Required = check.Required
+		switch {
+		case check.Status == detect.StatusMissing:
+			entry.State = ToolMissing
+		case check.Status == detect.StatusWarning && check.Version != "unknown":
+			entry.State = ToolOutdated
+		case check.Status == detect.StatusWarning && entry.Manager != "":
+			// A shim with no version selected fails to report one
+			entry.State = ToolMissing
+		default:
+			// OK, or installed with an unparseable version
+			entry.State = ToolOK
+		}
+		plan = append(plan, entry)
+	}
+	return plan
+}
+
 func (s *PrerequisitesStep) Check() (bool, error) {
-	// Check for key binaries
-	required := []string{"git", "java", "clojure", "bb", "docker", "emacs"}
-	for _, bin := range required {
-		if _, err := exec.LookPath(bin); err != nil {
+	for _, entry := range s.Plan() {
+		if entry.State != ToolOK {
 			return false, nil
 		}
 	}
@@ -27,96 +128,201 @@ func (s *PrerequisitesStep) Check() (bool, error) {
 }
 
 func (s *PrerequisitesStep) Run() error {
-	switch s.Platform {
-	case "darwin":
-		return s.installDarwin()
-	case "linux":
-		return s.installLinux()
-	default:
-		return fmt.Errorf("unsupported platform: %s", s.Platform)
-	}
-}
+	plan := s.Plan()
 
-func (s *PrerequisitesStep) installDarwin() error {
-	// Check if Homebrew is available
-	if _, err := exec.LookPath("brew"); err != nil {
-		return fmt.Errorf("Homebrew not found - please install from https://brew.sh")
+	var pending []ToolPlan
+	for _, entry := range plan {
+		if entry.State != ToolOK {
+			pending = append(pending, entry)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
 	}
 
-	packages := []string{
-		"git", "openjdk@17", "clojure/tools/clojure", "borkdude/brew/babashka",
-		"docker", "emacs-plus@29",
+	installer, err := NewInstaller(s.Platform)
+	if err != nil && !s.UserMode && s.VersionManager == "" && !s.Offline {
+		return err
+	}
+	var managers []detect.VersionManager
+	if s.VersionManager != "" {
+		managers = detect.DetectVersionManagers(DefaultHiveMCPDir())
 	}
+	outcomes := make([]ToolOutcome, 0, len(pending))
+	for _, entry := range pending {
+		fmt.Printf("    %s: %s", entry.Name, entry.State)
+		if entry.State == ToolOutdated {
+			fmt.Printf(" (%s < %s)", entry.Version, entry.Required)
+		}
+		fmt.Println()
+
+		if s.Offline {
+			outcomes = append(outcomes, ToolOutcome{
+				Tool:  entry.Tool,
+				State: entry.State,
+				Err:   installOffline(entry),
+			})
+			continue
+		}
+		if m, ok := resolveVersionManager(managers, s.VersionManager, entry.Tool); ok {
+			outcomes = append(outcomes, ToolOutcome{
+				Tool:  entry.Tool,
+				State: entry.State,
+				Err:   installWithManager(m, entry.Tool),
+			})
+			continue
+		}
+		if entry.Manager != "" {
+			// A system package would be shadowed by the manager's shim
+			outcomes = append(outcomes, ToolOutcome{
+				Tool:  entry.Tool,
+				State: entry.State,
+				Err: fmt.Errorf("provided by %s; install a newer version with %s or rerun with --version-manager %s",
+					entry.Manager, entry.Manager, entry.Manager),
+			})
+			continue
+		}
+		if installer == nil && !s.UserMode {
+			outcomes = append(outcomes, ToolOutcome{Tool: entry.Tool, State: entry.State, Err: err})
+			continue
+		}
+
+		if s.UserMode && (installer == nil || installer.NeedsRoot()) {
+			outcomes = append(outcomes, ToolOutcome{
+				Tool:  entry.Tool,
+				State: entry.State,
+				Err:   s.installUserLocal(installer, entry),
+			})
+			continue
+		}
 
-	for _, pkg := range packages {
-		cmd := exec.Command("brew", "install", pkg)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		// Continue even if some packages fail (might already be installed differently)
-		cmd.Run()
+		outcomes = append(outcomes, ToolOutcome{
+			Tool:  entry.Tool,
+			State: entry.State,
+			Err:   s.installTool(installer, entry),
+		})
 	}
 
-	return nil
+	return reportOutcomes(outcomes)
 }
 
-func (s *PrerequisitesStep) installLinux() error {
-	// Check if apt is available
-	if _, err := exec.LookPath("apt"); err != nil {
-		return fmt.Errorf("apt not found - this step requires Debian/Ubuntu")
-	}
+// errNeedsAdmin marks tools that can't be installed without root
+var errNeedsAdmin = errors.New("requires a system install")
 
-	// Install basic packages via apt
-	aptPkgs := []string{"git", "openjdk-17-jdk", "docker.io", "emacs"}
-	cmd := exec.Command("sudo", append([]string{"apt", "install", "-y"}, aptPkgs...)...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("apt install failed: %w", err)
+// installUserLocal installs a tool into ~/.local without sudo, or
+// records it as an admin item when only a system install will do
+func (s *PrerequisitesStep) installUserLocal(installer Installer, entry ToolPlan) error {
+	switch entry.Tool {
+	case "clojure":
+		return installClojureUser()
+	case "bb":
+		return installBabashkaUser()
+	case "java":
+		return installJDKUser()
 	}
 
-	// Install Clojure
-	if err := s.installClojureLinux(); err != nil {
-		return err
+	item := entry.Name
+	if installer != nil {
+		if pkg, ok := PackageName(installer.Name(), entry.Tool); ok {
+			item = fmt.Sprintf("%s (%s package %s)", entry.Name, installer.Name(), pkg)
+		}
 	}
+	if entry.State == ToolOutdated {
+		item += fmt.Sprintf(" - installed %s, need %s+", entry.Version, entry.Required)
+	}
+	s.AdminItems = append(s.AdminItems, item)
+	return errNeedsAdmin
+}
 
-	// Install Babashka
-	if err := s.installBabashkaLinux(); err != nil {
-		return err
+// installOffline installs a tool from the pinned tarballs. Without
+// root the tools go into ~/.local like a --user install.
+func installOffline(entry ToolPlan) error {
+	prefix := systemPrefix
+	if os.Geteuid() != 0 {
+		prefix = userPrefix()
+		ensureUserBinOnPath()
 	}
 
-	return nil
+	switch entry.Tool {
+	case "clojure":
+		return installClojureTools(prefix, prefix)
+	case "bb":
+		return installBabashkaBinary(filepath.Join(prefix, "bin"))
+	case "java":
+		return installJDKUser()
+	}
+	return fmt.Errorf("%s isn't in the offline bundle; install it from your distribution media", entry.Name)
 }
 
-func (s *PrerequisitesStep) installClojureLinux() error {
-	// Check if already installed
-	if _, err := exec.LookPath("clojure"); err == nil {
-		return nil
+// installTool installs a missing tool or upgrades an outdated one,
+// using the distro package when there is one and the pinned release
+// tarball otherwise
+func (s *PrerequisitesStep) installTool(installer Installer, entry ToolPlan) error {
+	pkg, ok := PackageName(installer.Name(), entry.Tool)
+	if !ok {
+		switch entry.Tool {
+		case "clojure":
+			return installClojureSystem()
+		case "bb":
+			return installBabashkaSystem()
+		default:
+			return fmt.Errorf("no %s package for %s", entry.Tool, installer.Name())
+		}
 	}
 
-	// Install via official script
-	script := `
-curl -L -O https://github.com/clojure/brew-install/releases/latest/download/linux-install.sh
-chmod +x linux-install.sh
-sudo ./linux-install.sh
-rm linux-install.sh
-`
-	cmd := exec.Command("bash", "-c", script)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	if entry.State == ToolOutdated && !versionedPackages[entry.Tool] {
+		return installer.Upgrade(pkg)
+	}
+	return installer.Install(pkg)
 }
 
-func (s *PrerequisitesStep) installBabashkaLinux() error {
-	// Check if already installed
-	if _, err := exec.LookPath("bb"); err == nil {
-		return nil
+// reportOutcomes prints per-tool results and summarises failures and
+// tools left for an administrator
+func reportOutcomes(outcomes []ToolOutcome) error {
+	var failed, admin []string
+	for _, o := range outcomes {
+		verb := "installed"
+		if o.State == ToolOutdated {
+			verb = "upgraded"
+		}
+
+		switch {
+		case errors.Is(o.Err, errNeedsAdmin):
+			fmt.Printf("    ! %s: %s, left for your administrator\n", o.Tool, o.State)
+			admin = append(admin, o.Tool)
+		case o.Err != nil:
+			fmt.Printf("    ✗ %s: %v\n", o.Tool, o.Err)
+			failed = append(failed, o.Tool)
+		default:
+			fmt.Printf("    ✓ %s: %s\n", o.Tool, verb)
+		}
 	}
 
-	// Install via official script
-	cmd := exec.Command("bash", "-c", "curl -sLO https://raw.githubusercontent.com/babashka/babashka/master/install && chmod +x install && sudo ./install && rm install")
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	var problems []string
+	if len(failed) > 0 {
+		problems = append(problems, "failed to install: "+strings.Join(failed, ", "))
+	}
+	if len(admin) > 0 {
+		problems = append(problems, "needs a system install: "+strings.Join(admin, ", "))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// installClojureSystem installs the Clojure CLI from the pinned
+// clojure-tools tarball into /usr/local
+func installClojureSystem() error {
+	return installAsRoot(systemPrefix, func(dest string) error {
+		return installClojureTools(systemPrefix, dest)
+	})
+}
+
+// installBabashkaSystem installs the pinned bb release into
+// /usr/local/bin
+func installBabashkaSystem() error {
+	return installAsRoot(filepath.Join(systemPrefix, "bin"), installBabashkaBinary)
 }
 
 func (s *PrerequisitesStep) Rollback() error {
@@ -0,0 +1,205 @@
+package setup
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+)
+
+// backupTimeFormat sorts lexically in time order
+const backupTimeFormat = "20060102T150405.000"
+
+// backupsPerFile is how many backups of each file are kept
+const backupsPerFile = 10
+
+// BackupDir is where copies of user config files are kept before hive
+// edits them
+func BackupDir() string {
+	return filepath.Join(util.StateDir(), "backups")
+}
+
+// resolveFile follows symlinks so edits land in the real file (for
+// example a dotfile managed by stow or chezmoi) instead of replacing
+// the link. A missing file resolves through its existing parents.
+func resolveFile(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return resolved, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	if target, err := os.Readlink(path); err == nil {
+		// Dangling link: create the file it points to
+		if !filepath.IsAbs(target) {
+			target = filepath.Join(filepath.Dir(path), target)
+		}
+		return target, nil
+	}
+	return path, nil
+}
+
+// editFile rewrites a user config file through edit. The original is
+// backed up first, and the new content is written to a temp file in
+// the same directory and renamed over it with the original mode.
+func editFile(path string, edit func(content string) string) error {
+	target, err := resolveFile(path)
+	if err != nil {
+		return err
+	}
+
+	mode := os.FileMode(0644)
+	data, err := os.ReadFile(target)
+	exists := err == nil
+	switch {
+	case exists:
+		if info, err := os.Stat(target); err == nil {
+			mode = info.Mode().Perm()
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	content := string(data)
+	updated := edit(content)
+	if exists && updated == content {
+		return nil
+	}
+
+	if exists {
+		if err := backupFile(target, data); err != nil {
+			return err
+		}
+	} else if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+	}
+	return writeAtomic(target, []byte(updated), mode)
+}
+
+// removeFile deletes a file hive created, keeping a backup
+func removeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := backupFile(path, data); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// writeAtomic replaces path with data via a temp file and rename
+func writeAtomic(path string, data []byte, mode os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".hive-*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Backup is a saved copy of a config file
+type Backup struct {
+	Path string // file the backup was taken from
+	File string // the backup itself
+	Time time.Time
+}
+
+// backupFile saves data as a backup of path. The original path is
+// encoded in the backup's name so it can be restored later. Only the
+// newest backupsPerFile backups of path are kept.
+func backupFile(path string, data []byte) error {
+	if err := os.MkdirAll(BackupDir(), 0700); err != nil {
+		return fmt.Errorf("failed to create %s: %w", BackupDir(), err)
+	}
+	// Backup names must stay in order, so two edits within a
+	// millisecond don't replace or reorder each other
+	stamp := time.Now().Truncate(time.Millisecond)
+	if backups, _ := Backups(); len(backups) > 0 && !stamp.After(backups[0].Time) {
+		stamp = backups[0].Time.Add(time.Millisecond)
+	}
+	name := stamp.Format(backupTimeFormat) + "_" + url.PathEscape(path)
+	if err := os.WriteFile(filepath.Join(BackupDir(), name), data, 0600); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+
+	backups, err := Backups()
+	if err != nil {
+		return nil
+	}
+	kept := 0
+	for _, b := range backups {
+		if b.Path != path {
+			continue
+		}
+		if kept++; kept > backupsPerFile {
+			os.Remove(b.File)
+		}
+	}
+	return nil
+}
+
+// Backups lists the saved backups, newest first
+func Backups() ([]Backup, error) {
+	entries, err := os.ReadDir(BackupDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		stamp, escaped, ok := strings.Cut(e.Name(), "_")
+		if !ok || e.IsDir() {
+			continue
+		}
+		t, err := time.ParseInLocation(backupTimeFormat, stamp, time.Local)
+		if err != nil {
+			continue
+		}
+		path, err := url.PathUnescape(escaped)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, Backup{Path: path, File: filepath.Join(BackupDir(), e.Name()), Time: t})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].Time.After(backups[j].Time) })
+	return backups, nil
+}
+
+// RestoreBackup writes a backup back to its original file. The current
+// contents are backed up first, so a restore can itself be undone.
+func RestoreBackup(b Backup) error {
+	data, err := os.ReadFile(b.File)
+	if err != nil {
+		return err
+	}
+	return editFile(b.Path, func(string) string {
+		return string(data)
+	})
+}
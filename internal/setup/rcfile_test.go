@@ -0,0 +1,176 @@
+package setup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// scratchHome points HOME and the state dir at a temp dir
+func scratchHome(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, ".local", "state"))
+	return home
+}
+
+func appendLine(line string) func(string) string {
+	return func(content string) string { return content + line + "\n" }
+}
+
+func TestEditFileKeepsSymlink(t *testing.T) {
+	home := scratchHome(t)
+	dotfiles := filepath.Join(home, "dotfiles")
+	if err := os.MkdirAll(dotfiles, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	real := filepath.Join(dotfiles, "bashrc")
+	if err := os.WriteFile(real, []byte("alias ll='ls -l'\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(home, ".bashrc")
+	if err := os.Symlink("dotfiles/bashrc", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := editFile(link, appendLine("export A=1")); err != nil {
+		t.Fatalf("editFile: %v", err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("%s is no longer a symlink", link)
+	}
+	if data, _ := os.ReadFile(real); string(data) != "alias ll='ls -l'\nexport A=1\n" {
+		t.Errorf("link target = %q", data)
+	}
+}
+
+func TestEditFileDanglingSymlink(t *testing.T) {
+	home := scratchHome(t)
+	link := filepath.Join(home, ".zshrc")
+	if err := os.Symlink("dotfiles/zshrc", link); err != nil {
+		t.Fatal(err)
+	}
+	if err := editFile(link, appendLine("export A=1")); err != nil {
+		t.Fatalf("editFile: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(home, "dotfiles", "zshrc")); err != nil || string(data) != "export A=1\n" {
+		t.Errorf("link target = %q, %v", data, err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("%s is no longer a symlink", link)
+	}
+}
+
+func TestEditFileKeepsMode(t *testing.T) {
+	home := scratchHome(t)
+	path := filepath.Join(home, ".profile")
+	if err := os.WriteFile(path, []byte("umask 077\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := editFile(path, appendLine("export A=1")); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("mode = %v, %v; want 0600", info.Mode().Perm(), err)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(home, ".profile.hive-*"))
+	if len(leftovers) > 0 {
+		t.Errorf("temp files left behind: %v", leftovers)
+	}
+}
+
+func TestMarkedSectionKeepsTrailingNewline(t *testing.T) {
+	home := scratchHome(t)
+	start, end := markerLines("#")
+	for _, newline := range []bool{true, false} {
+		path := filepath.Join(home, ".bashrc")
+		original := joinLines([]string{"alias ll='ls -l'", "", start, "export A=1", end}, newline)
+		if err := os.WriteFile(path, []byte(original), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := writeMarkedSection(path, "#", []string{"export A=2"}); err != nil {
+			t.Fatal(err)
+		}
+		data, _ := os.ReadFile(path)
+		if want := joinLines([]string{"alias ll='ls -l'", "", start, "export A=2", end}, newline); string(data) != want {
+			t.Errorf("rewritten = %q, want %q", data, want)
+		}
+
+		if err := removeMarkedSection(path, "#"); err != nil {
+			t.Fatal(err)
+		}
+		data, _ = os.ReadFile(path)
+		if want := joinLines([]string{"alias ll='ls -l'"}, newline); string(data) != want {
+			t.Errorf("stripped = %q, want %q", data, want)
+		}
+	}
+}
+
+func TestBackupRestoreRoundTrip(t *testing.T) {
+	home := scratchHome(t)
+	path := filepath.Join(home, ".bashrc")
+	if err := os.WriteFile(path, []byte("original\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := editFile(path, func(string) string { return "edited\n" }); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := Backups()
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Backups = %v, %v; want one", backups, err)
+	}
+	if backups[0].Path != path {
+		t.Errorf("backup path = %q, want %q", backups[0].Path, path)
+	}
+	if info, err := os.Stat(backups[0].File); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("backup is not private: %v", err)
+	}
+
+	if err := RestoreBackup(backups[0]); err != nil {
+		t.Fatalf("RestoreBackup: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "original\n" {
+		t.Errorf("restored = %q", data)
+	}
+
+	// The restore backed up the edited contents, so it can be undone
+	backups, _ = Backups()
+	if len(backups) != 2 {
+		t.Fatalf("got %d backups after restoring, want 2", len(backups))
+	}
+	if data, _ := os.ReadFile(backups[0].File); string(data) != "edited\n" {
+		t.Errorf("newest backup = %q, want the edited contents", data)
+	}
+}
+
+func TestBackupsArePruned(t *testing.T) {
+	home := scratchHome(t)
+	path := filepath.Join(home, ".bashrc")
+	other := filepath.Join(home, ".zshrc")
+	if err := backupFile(other, []byte("zsh")); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < backupsPerFile+5; i++ {
+		if err := backupFile(path, []byte{byte('a' + i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := Backups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count := map[string]int{}
+	for _, b := range backups {
+		count[b.Path]++
+	}
+	if count[path] != backupsPerFile || count[other] != 1 {
+		t.Errorf("kept %v, want %d of %s and 1 of %s", count, backupsPerFile, path, other)
+	}
+	if data, _ := os.ReadFile(backups[0].File); string(data) != string(rune('a'+backupsPerFile+4)) {
+		t.Errorf("newest backup = %q, want the last one written", data)
+	}
+}
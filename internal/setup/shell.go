@@ -1,16 +1,32 @@
 package setup
 
 import (
-	"bufio"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
+// ShellMode selects what the managed shell block contains
+type ShellMode string
+
+const (
+	// ShellLiteral writes the variables' values into the block
+	ShellLiteral ShellMode = "literal"
+	// ShellEval writes a single line that loads 'hive env' output, so
+	// the rc file never needs rewriting when values change
+	ShellEval ShellMode = "eval"
+)
+
 // ShellStep configures shell environment variables
 type ShellStep struct {
 	HiveMCPDir string
+	ExtraPath  []string          // directories prepended to PATH (e.g. ~/.local/bin)
+	Env        map[string]string // extra variables, e.g. env from config.yaml
+	Mode       ShellMode         // defaults to ShellLiteral
 }
 
 func (s *ShellStep) Name() string {
@@ -24,133 +40,165 @@ func (s *ShellStep) hiveMCPDir() string {
 	return DefaultHiveMCPDir()
 }
 
-// envVars returns the environment variables to set
-func (s *ShellStep) envVars() map[string]string {
-	hiveMCP := s.hiveMCPDir()
-	return map[string]string{
-		"HIVE_MCP_DIR": hiveMCP,
-		"BB_MCP_DIR":   hiveMCP,
-	}
+// envVar is an environment variable exported by the managed block
+type envVar struct {
+	Name  string
+	Value string
 }
 
-// shellConfigFiles returns paths to shell config files that exist
-func shellConfigFiles() []string {
-	home, _ := os.UserHomeDir()
-	candidates := []string{
-		filepath.Join(home, ".bashrc"),
-		filepath.Join(home, ".zshrc"),
+// envVars returns the environment variables to set, in block order.
+// Env entries follow the install dirs in name order, and override them
+// when they share a name.
+func (s *ShellStep) envVars() []envVar {
+	hiveMCP := s.hiveMCPDir()
+	vars := []envVar{
+		{"HIVE_MCP_DIR", hiveMCP},
+		{"BB_MCP_DIR", hiveMCP},
 	}
 
-	var existing []string
-	for _, f := range candidates {
-		if _, err := os.Stat(f); err == nil {
-			existing = append(existing, f)
+	names := make([]string, 0, len(s.Env))
+	for name := range s.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		i := slices.IndexFunc(vars, func(v envVar) bool { return v.Name == name })
+		if i >= 0 {
+			vars[i].Value = s.Env[name]
+		} else {
+			vars = append(vars, envVar{name, s.Env[name]})
 		}
 	}
-	return existing
+	return vars
 }
 
-// marker identifies hive-mcp-cli managed section
-const shellMarker = "# hive-mcp-cli managed"
-
+// Check parses the managed block of every shell config and compares it
+// with the desired variables, so a moved install dir is picked up
 func (s *ShellStep) Check() (bool, error) {
-	files := shellConfigFiles()
-	if len(files) == 0 {
-		return false, nil
-	}
-
-	// Check if any shell config already has our marker
-	for _, f := range files {
-		content, err := os.ReadFile(f)
-		if err != nil {
-			continue
-		}
-		if strings.Contains(string(content), shellMarker) {
-			return true, nil
+	for _, rc := range shellConfigs() {
+		body, ok := rc.section()
+		if !ok || !s.upToDate(rc, body) {
+			return false, nil
 		}
 	}
-	return false, nil
+	return true, nil
 }
 
-func (s *ShellStep) Run() error {
-	files := shellConfigFiles()
-	if len(files) == 0 {
-		return fmt.Errorf("no shell config found (.bashrc or .zshrc)")
+func (s *ShellStep) upToDate(rc shellRC, body []string) bool {
+	current := rc.vars(body)
+	if s.Mode == ShellEval {
+		return len(current) == 0 && slices.Contains(body, rc.renderer.Eval(rc.Shell)) &&
+			(len(s.ExtraPath) == 0 || slices.Contains(body, rc.renderer.PrependPath(s.ExtraPath)))
 	}
 
-	// Build the config block
 	vars := s.envVars()
-	var lines []string
-	lines = append(lines, "", shellMarker+" - START")
-	for key, value := range vars {
-		lines = append(lines, fmt.Sprintf("export %s=\"%s\"", key, value))
+	if len(current) != len(vars) || slices.ContainsFunc(body, isEvalLine) {
+		return false
 	}
-	lines = append(lines, shellMarker+" - END", "")
-	block := strings.Join(lines, "\n")
-
-	// Append to each shell config
-	for _, f := range files {
-		file, err := os.OpenFile(f, os.O_APPEND|os.O_WRONLY, 0644)
-		if err != nil {
-			return fmt.Errorf("failed to open %s: %w", f, err)
+	for _, v := range vars {
+		if value, ok := current[v.Name]; !ok || value != v.Value {
+			return false
 		}
-		defer file.Close()
+	}
+	return len(s.ExtraPath) == 0 || slices.Contains(body, rc.renderer.PrependPath(s.ExtraPath))
+}
 
-		if _, err := file.WriteString(block); err != nil {
-			return fmt.Errorf("failed to write to %s: %w", f, err)
+func (s *ShellStep) Run() error {
+	vars := s.envVars()
+	for _, rc := range shellConfigs() {
+		if err := rc.write(s.body(rc, vars)); err != nil {
+			return err
 		}
 	}
-
 	return nil
 }
 
-func (s *ShellStep) Rollback() error {
-	files := shellConfigFiles()
+// body renders the block for rc. Without ExtraPath, the PATH line of
+// an earlier --user setup is kept. Eval blocks keep PATH as a literal
+// line too, so it survives switching modes.
+func (s *ShellStep) body(rc shellRC, vars []envVar) []string {
+	var body []string
+	if s.Mode == ShellEval {
+		body = rc.render(nil, s.ExtraPath)
+		body = append([]string{rc.renderer.Eval(rc.Shell)}, body...)
+	} else {
+		body = rc.render(vars, s.ExtraPath)
+	}
+	if len(s.ExtraPath) > 0 {
+		return body
+	}
+	existing, _ := rc.section()
+	for _, line := range existing {
+		if name, _, ok := rc.renderer.Parse(line); ok && name != "PATH" {
+			continue
+		}
+		if isEvalLine(line) {
+			continue
+		}
+		if strings.TrimSpace(line) != "" {
+			body = append(body, line)
+		}
+	}
+	return body
+}
 
-	for _, f := range files {
-		if err := removeMarkedSection(f); err != nil {
-			return fmt.Errorf("failed to rollback %s: %w", f, err)
+func (s *ShellStep) Rollback() error {
+	for _, rc := range managedConfigs() {
+		if err := rc.remove(); err != nil {
+			return fmt.Errorf("failed to rollback %s: %w", rc.Path, err)
 		}
 	}
 	return nil
 }
 
-// removeMarkedSection removes the hive-mcp-cli managed section from a file
-func removeMarkedSection(path string) error {
-	file, err := os.Open(path)
-	if err != nil {
-		return err
+// ShellBlockFiles returns the shell config files containing the
+// managed section
+func ShellBlockFiles() []string {
+	var files []string
+	for _, rc := range managedConfigs() {
+		if _, ok := rc.section(); ok {
+			files = append(files, rc.Path)
+		}
 	}
-	defer file.Close()
-
-	var lines []string
-	scanner := bufio.NewScanner(file)
-	inSection := false
+	return files
+}
 
-	for scanner.Scan() {
-		line := scanner.Text()
+// EnvShells are the formats accepted by Script
+var EnvShells = []string{"bash", "zsh", "sh", "fish", "nu", "json"}
 
-		if strings.Contains(line, shellMarker+" - START") {
-			inSection = true
-			continue
+// Script renders the variables the literal block would contain as code
+// for shell, or as a JSON object for "json". In JSON, PATH is a list
+// with ExtraPath ahead of the current PATH entries.
+func (s *ShellStep) Script(shell string) (string, error) {
+	vars := s.envVars()
+	if shell == "json" {
+		env := make(map[string]any, len(vars)+1)
+		for _, v := range vars {
+			env[v.Name] = v.Value
 		}
-		if strings.Contains(line, shellMarker+" - END") {
-			inSection = false
-			continue
+		if len(s.ExtraPath) > 0 {
+			var path []string
+			for _, dir := range s.ExtraPath {
+				path = append(path, os.ExpandEnv(dir))
+			}
+			for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+				if !slices.Contains(path, dir) {
+					path = append(path, dir)
+				}
+			}
+			env["PATH"] = path
 		}
-		if !inSection {
-			lines = append(lines, line)
+		data, err := json.MarshalIndent(env, "", "  ")
+		if err != nil {
+			return "", err
 		}
+		return string(data) + "\n", nil
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
+	renderer, ok := rendererFor(shell)
+	if !ok {
+		return "", fmt.Errorf("unknown shell %q (expected %s)", shell, strings.Join(EnvShells, ", "))
 	}
-
-	// Remove trailing empty lines that we added
-	for len(lines) > 0 && lines[len(lines)-1] == "" {
-		lines = lines[:len(lines)-1]
-	}
-
-	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+	lines := shellRC{Shell: shell, renderer: renderer}.render(vars, s.ExtraPath)
+	return strings.Join(lines, "\n") + "\n", nil
 }
@@ -0,0 +1,328 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+)
+
+// shellRC is a shell config file and the syntax its managed block is
+// written in
+type shellRC struct {
+	Shell string // "bash", "zsh", "sh", "fish" or "nu"
+	Path  string
+
+	// Owned files exist only for hive (fish's conf.d snippet) and are
+	// deleted on rollback instead of being edited
+	Owned bool
+
+	renderer shellRenderer
+}
+
+// shellRenderer writes environment variables in one shell's syntax
+type shellRenderer interface {
+	// Comment is the line comment prefix used for the block markers
+	Comment() string
+	Export(name, value string) string
+	PrependPath(dirs []string) string
+
+	// Parse reads back a variable written by Export
+	Parse(line string) (name, value string, ok bool)
+
+	// Eval runs 'hive env' for shell and loads its output
+	Eval(shell string) string
+}
+
+// rendererFor returns the renderer for a shell name
+func rendererFor(shell string) (shellRenderer, bool) {
+	switch shell {
+	case "bash", "zsh", "sh":
+		return posixRenderer{}, true
+	case "fish":
+		return fishRenderer{}, true
+	case "nu":
+		return nuRenderer{}, true
+	}
+	return nil, false
+}
+
+// isEvalLine reports whether line is an eval block line
+func isEvalLine(line string) bool {
+	return strings.Contains(line, "hive env --shell ")
+}
+
+var (
+	posixExport = regexp.MustCompile(`^export ([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+	fishExport  = regexp.MustCompile(`^set -gx ([A-Za-z_][A-Za-z0-9_]*) (.*)$`)
+	nuExport    = regexp.MustCompile(`^\$env\.([A-Za-z_][A-Za-z0-9_]*) = (.*)$`)
+)
+
+// parseExport matches line against re and unquotes the value
+func parseExport(re *regexp.Regexp, line string) (string, string, bool) {
+	m := re.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", "", false
+	}
+	value, ok := unquoteShell(m[2])
+	return m[1], value, ok
+}
+
+// doubleQuote wraps s in double quotes, backslash-escaping the
+// characters in special
+func doubleQuote(s, special string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// unquoteShell reverses doubleQuote; single-quoted and bare words are
+// taken literally
+func unquoteShell(s string) (string, bool) {
+	switch {
+	case len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'':
+		return s[1 : len(s)-1], true
+	case len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"':
+		var b strings.Builder
+		inner := s[1 : len(s)-1]
+		for i := 0; i < len(inner); i++ {
+			if inner[i] == '\\' && i+1 < len(inner) {
+				i++
+			}
+			b.WriteByte(inner[i])
+		}
+		return b.String(), true
+	case strings.ContainsAny(s, " \t\"'"):
+		return "", false
+	}
+	return s, true
+}
+
+// posixRenderer covers bash, zsh and POSIX sh (~/.profile)
+type posixRenderer struct{}
+
+func (posixRenderer) Comment() string { return "#" }
+
+func (posixRenderer) Export(name, value string) string {
+	return fmt.Sprintf("export %s=%s", name, doubleQuote(value, "\\\"$`"))
+}
+
+func (posixRenderer) Parse(line string) (string, string, bool) {
+	return parseExport(posixExport, line)
+}
+
+func (posixRenderer) PrependPath(dirs []string) string {
+	return fmt.Sprintf("export PATH=\"%s:$PATH\"", strings.Join(dirs, ":"))
+}
+
+func (posixRenderer) Eval(shell string) string {
+	return fmt.Sprintf(`command -v hive >/dev/null 2>&1 && eval "$(hive env --shell %s)"`, shell)
+}
+
+type fishRenderer struct{}
+
+func (fishRenderer) Comment() string { return "#" }
+
+func (fishRenderer) Export(name, value string) string {
+	return fmt.Sprintf("set -gx %s %s", name, doubleQuote(value, "\\\"$"))
+}
+
+func (fishRenderer) Parse(line string) (string, string, bool) {
+	return parseExport(fishExport, line)
+}
+
+func (fishRenderer) PrependPath(dirs []string) string {
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = `"` + dir + `"`
+	}
+	return fmt.Sprintf("set -gx PATH %s $PATH", strings.Join(quoted, " "))
+}
+
+func (fishRenderer) Eval(shell string) string {
+	return fmt.Sprintf("command -q hive; and hive env --shell %s | source", shell)
+}
+
+type nuRenderer struct{}
+
+func (nuRenderer) Comment() string { return "#" }
+
+func (nuRenderer) Export(name, value string) string {
+	return fmt.Sprintf("$env.%s = %s", name, doubleQuote(value, "\\\""))
+}
+
+func (nuRenderer) Parse(line string) (string, string, bool) {
+	return parseExport(nuExport, line)
+}
+
+func (nuRenderer) PrependPath(dirs []string) string {
+	quoted := make([]string, len(dirs))
+	for i, dir := range dirs {
+		quoted[i] = nuString(dir)
+	}
+	return fmt.Sprintf("$env.PATH = ($env.PATH | split row (char esep) | prepend [%s])", strings.Join(quoted, " "))
+}
+
+// Eval loads the JSON output, since nushell can't source generated
+// code at runtime
+func (nuRenderer) Eval(shell string) string {
+	return "hive env --shell json | from json | load-env"
+}
+
+// nuString quotes s for nushell. A leading $HOME, as used by the
+// POSIX blocks, becomes an interpolation of $env.HOME.
+func nuString(s string) string {
+	if rest, ok := strings.CutPrefix(s, "$HOME"); ok {
+		return `$"($env.HOME)` + rest + `"`
+	}
+	return `"` + s + `"`
+}
+
+// shellConfigs returns the shell config files that get the managed
+// block: existing bash and zsh ($ZDOTDIR aware) rc files, a conf.d
+// snippet when fish is configured, and env.nu when nushell is.
+// ~/.profile is only used without a bash or zsh rc file, since bash
+// login shells commonly source ~/.bashrc from it and would get the
+// block twice; with nothing else present it is created so login
+// shells still pick the variables up.
+func shellConfigs() []shellRC {
+	home, _ := os.UserHomeDir()
+	profile := shellRC{Shell: "sh", Path: filepath.Join(home, ".profile"), renderer: posixRenderer{}}
+	candidates := []shellRC{
+		{Shell: "bash", Path: filepath.Join(home, ".bashrc"), renderer: posixRenderer{}},
+		{Shell: "zsh", Path: filepath.Join(detect.ZshDir(), ".zshrc"), renderer: posixRenderer{}},
+	}
+
+	var configs []shellRC
+	for _, rc := range candidates {
+		if _, err := os.Stat(rc.Path); err == nil {
+			configs = append(configs, rc)
+		}
+	}
+	if _, err := os.Stat(profile.Path); err == nil && len(configs) == 0 {
+		configs = append(configs, profile)
+	}
+
+	fishDir := detect.FishConfigDir()
+	if _, err := os.Stat(fishDir); err == nil || hasCommand("fish") {
+		configs = append(configs, shellRC{
+			Shell:    "fish",
+			Path:     filepath.Join(fishDir, "conf.d", "hive.fish"),
+			Owned:    true,
+			renderer: fishRenderer{},
+		})
+	}
+
+	if hasCommand("nu") {
+		envNu := filepath.Join(detect.NuConfigDir(), "env.nu")
+		if _, err := os.Stat(envNu); err == nil {
+			configs = append(configs, shellRC{Shell: "nu", Path: envNu, renderer: nuRenderer{}})
+		}
+	}
+
+	if len(configs) == 0 {
+		configs = append(configs, profile)
+	}
+	return configs
+}
+
+// managedConfigs is shellConfigs plus ~/.profile, which may still hold
+// a block from before a bash or zsh rc file existed
+func managedConfigs() []shellRC {
+	configs := shellConfigs()
+	home, _ := os.UserHomeDir()
+	profile := filepath.Join(home, ".profile")
+	if !slices.ContainsFunc(configs, func(rc shellRC) bool { return rc.Path == profile }) {
+		configs = append(configs, shellRC{Shell: "sh", Path: profile, renderer: posixRenderer{}})
+	}
+	return configs
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// render returns the block body for vars (in the given order) and
+// extra PATH entries
+func (rc shellRC) render(vars []envVar, extraPath []string) []string {
+	var body []string
+	for _, v := range vars {
+		body = append(body, rc.renderer.Export(v.Name, v.Value))
+	}
+	if len(extraPath) > 0 {
+		body = append(body, rc.renderer.PrependPath(extraPath))
+	}
+	return body
+}
+
+// section returns the current managed block body, if any
+func (rc shellRC) section() ([]string, bool) {
+	content, err := os.ReadFile(rc.Path)
+	if err != nil {
+		return nil, false
+	}
+	return markedSection(string(content), rc.renderer.Comment())
+}
+
+// vars parses the variables exported by a block body
+func (rc shellRC) vars(body []string) map[string]string {
+	vars := make(map[string]string)
+	for _, line := range body {
+		if name, value, ok := rc.renderer.Parse(line); ok && name != "PATH" {
+			vars[name] = value
+		}
+	}
+	return vars
+}
+
+// write creates or replaces the managed block
+func (rc shellRC) write(body []string) error {
+	return writeMarkedSection(rc.Path, rc.renderer.Comment(), body)
+}
+
+// remove strips the managed block, deleting files owned by hive
+func (rc shellRC) remove() error {
+	if _, ok := rc.section(); !ok {
+		return nil
+	}
+	if rc.Owned {
+		return removeFile(rc.Path)
+	}
+	return removeMarkedSection(rc.Path, rc.renderer.Comment())
+}
+
+// ShellBlock is the managed block of one shell config file
+type ShellBlock struct {
+	Path string
+	Vars map[string]string
+	Eval bool // the block loads 'hive env' instead of setting values
+}
+
+// ReadShellBlocks parses the managed block of every shell config that
+// has one
+func ReadShellBlocks() []ShellBlock {
+	var blocks []ShellBlock
+	for _, rc := range shellConfigs() {
+		if body, ok := rc.section(); ok {
+			blocks = append(blocks, ShellBlock{
+				Path: rc.Path,
+				Vars: rc.vars(body),
+				Eval: slices.ContainsFunc(body, isEvalLine),
+			})
+		}
+	}
+	return blocks
+}
@@ -16,9 +16,9 @@ type Step interface {
 
 // Result captures the outcome of a step execution
 type Result struct {
-	StepName string
-	Skipped  bool
-	Error    error
+	StepName string `json:"step"`
+	Skipped  bool   `json:"skipped"`
+	Error    error  `json:"-"`
 }
 
 // Runner manages step execution with progress reporting
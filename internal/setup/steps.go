@@ -0,0 +1,133 @@
+package setup
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+	"github.com/hive-agi/hive-mcp-cli/internal/secrets"
+)
+
+// Options are the setup flags that shape the steps
+type Options struct {
+	UserMode       bool          // install into ~/.local without sudo
+	VersionManager string        // "", "auto", "mise", "asdf" or "sdkman"
+	ShellMode      ShellMode     // defaults to config.yaml's shell.mode
+	EmacsTimeout   time.Duration // defaults to DefaultEmacsReadyTimeout
+}
+
+// Validate rejects unknown version managers and shell modes
+func (o Options) Validate(cfg *config.Config) error {
+	vm := o.VersionManager
+	if vm != "" && vm != "auto" && !slices.Contains(SupportedVersionManagers(), vm) {
+		return fmt.Errorf("unknown version manager %q (expected auto, %s)",
+			vm, strings.Join(SupportedVersionManagers(), ", "))
+	}
+
+	mode := o.ShellMode
+	if mode == "" {
+		mode = ShellMode(cfg.Shell.Mode)
+	}
+	switch mode {
+	case "", ShellLiteral, ShellEval:
+	default:
+		return fmt.Errorf("unknown shell mode %q (expected literal or eval)", mode)
+	}
+	return nil
+}
+
+// Steps holds one of each setup step, configured from config.yaml and
+// Options. Callers may adjust the steps before running them.
+type Steps struct {
+	Clone         *CloneStep
+	Shell         *ShellStep
+	Prereqs       *PrerequisitesStep
+	Deps          *CloneDepsStep
+	EmacsPackages *DoomSyncStep
+	EmacsConfig   *EmacsConfigStep
+	Chroma        *ChromaStep
+	Ollama        *OllamaStep
+	EmacsDaemon   *EmacsDaemonStep
+	MCP           *MCPStep
+}
+
+// NamedStep pairs a step with the stable ID tools refer to it by
+type NamedStep struct {
+	ID   string
+	Step Step
+}
+
+// NewSteps builds the setup steps
+func NewSteps(cfg *config.Config, opts Options) *Steps {
+	shellMode := opts.ShellMode
+	if shellMode == "" {
+		shellMode = ShellMode(cfg.Shell.Mode)
+	}
+	emacsTimeout := opts.EmacsTimeout
+	if emacsTimeout == 0 {
+		emacsTimeout = DefaultEmacsReadyTimeout
+	}
+
+	s := &Steps{
+		Clone: &CloneStep{URL: cfg.Repo.URL, Ref: cfg.Repo.Ref, Depth: cfg.Repo.Depth},
+		Shell: &ShellStep{Env: cfg.Env, Mode: shellMode},
+		Prereqs: &PrerequisitesStep{
+			Platform:       detect.DetectPlatform(),
+			UserMode:       opts.UserMode,
+			VersionManager: opts.VersionManager,
+		},
+		Deps:          &CloneDepsStep{},
+		EmacsPackages: &DoomSyncStep{},
+		EmacsConfig:   &EmacsConfigStep{},
+		Chroma:        &ChromaStep{},
+		Ollama:        &OllamaStep{},
+		EmacsDaemon:   &EmacsDaemonStep{ReadyTimeout: emacsTimeout},
+		MCP:           &MCPStep{Secrets: secrets.InUse() && !secrets.PassphraseProtected()},
+	}
+	if opts.UserMode {
+		s.Shell.ExtraPath = []string{"$HOME/.local/bin"}
+	}
+	return s
+}
+
+// StepInfo describes a setup step without building it
+type StepInfo struct {
+	ID   string
+	Name string
+}
+
+// stepInfo lists the steps in the order setup runs them
+var stepInfo = []StepInfo{
+	{"clone", "Clone hive-mcp repository"},
+	{"shell", "Configure shell environment"},
+	{"prerequisites", "Install system prerequisites"},
+	{"deps", "Download Clojure dependencies"},
+	{"emacs_packages", "Sync Emacs packages"},
+	{"emacs_config", "Configure Emacs to load hive-mcp"},
+	{"chroma", "Start Docker services (Chroma)"},
+	{"ollama", "Setup Ollama with nomic-embed-text model"},
+	{"emacs_daemon", "Start Emacs daemon"},
+	{"mcp", "Register MCP server with Claude CLI"},
+}
+
+// StepInfos returns the IDs and generic names of the setup steps in
+// the order setup runs them. Unlike NewSteps it detects nothing.
+func StepInfos() []StepInfo {
+	return slices.Clone(stepInfo)
+}
+
+// List returns the steps in the order setup runs them
+func (s *Steps) List() []NamedStep {
+	steps := []Step{
+		s.Clone, s.Shell, s.Prereqs, s.Deps, s.EmacsPackages,
+		s.EmacsConfig, s.Chroma, s.Ollama, s.EmacsDaemon, s.MCP,
+	}
+	named := make([]NamedStep, len(steps))
+	for i, step := range steps {
+		named[i] = NamedStep{stepInfo[i].ID, step}
+	}
+	return named
+}
@@ -0,0 +1,29 @@
+package setup
+
+import (
+	"testing"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+)
+
+// StepInfos stands in for the built steps when registering tools, so
+// it has to stay in step with List
+func TestStepInfosMatchList(t *testing.T) {
+	scratchHome(t)
+	steps := NewSteps(&config.Config{}, Options{})
+	steps.EmacsPackages.Config = &EmacsConfig{} // no distribution detected
+
+	infos := StepInfos()
+	named := steps.List()
+	if len(infos) != len(named) {
+		t.Fatalf("%d step infos for %d steps", len(infos), len(named))
+	}
+	for i, info := range infos {
+		if info.ID != named[i].ID {
+			t.Errorf("step %d: info ID %q, List ID %q", i, info.ID, named[i].ID)
+		}
+		if got := named[i].Step.Name(); info.Name != got {
+			t.Errorf("step %q: info name %q, step name %q", info.ID, info.Name, got)
+		}
+	}
+}
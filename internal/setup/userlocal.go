@@ -0,0 +1,374 @@
+package setup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/download"
+)
+
+// systemPrefix is where system-wide installs of the pinned tarballs go
+const systemPrefix = "/usr/local"
+
+// userPrefix is where user-local installs go (~/.local)
+func userPrefix() string {
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local")
+}
+
+// UserBinDir is the directory user-local installs put binaries in
+func UserBinDir() string {
+	return filepath.Join(userPrefix(), "bin")
+}
+
+// userJDKDir holds the JDK unpacked by installJDKUser
+func userJDKDir() string {
+	return filepath.Join(userPrefix(), "share", "hive", "jdk-17")
+}
+
+// ensureUserBinOnPath makes ~/.local/bin visible to later steps in
+// this process; the shell step persists it for new shells
+func ensureUserBinOnPath() {
+	prependPath(UserBinDir())
+}
+
+// prependPath puts bin at the front of this process's PATH unless it
+// is already listed
+func prependPath(bin string) {
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == bin {
+			return
+		}
+	}
+	os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// installClojureUser installs the Clojure CLI into ~/.local
+func installClojureUser() error {
+	return installClojureTools(userPrefix(), userPrefix())
+}
+
+// installBabashkaUser installs bb into ~/.local/bin
+func installBabashkaUser() error {
+	return installBabashkaBinary(UserBinDir())
+}
+
+// jdkArtifact returns the pinned Temurin 17 tarball for this platform
+func jdkArtifact() (string, error) {
+	arch := map[string]string{"amd64": "x64", "arm64": "aarch64"}[runtime.GOARCH]
+	osName := map[string]string{"linux": "linux", "darwin": "mac"}[runtime.GOOS]
+	if arch == "" || osName == "" {
+		return "", fmt.Errorf("no JDK build for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("jdk-17-%s-%s", osName, arch), nil
+}
+
+// babashkaArtifact returns the pinned bb release tarball for this platform
+func babashkaArtifact() (string, error) {
+	arch := map[string]string{"amd64": "amd64", "arm64": "aarch64"}[runtime.GOARCH]
+	osName := map[string]string{"linux": "linux", "darwin": "macos"}[runtime.GOOS]
+	if arch == "" || osName == "" {
+		return "", fmt.Errorf("no Babashka build for %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	return fmt.Sprintf("babashka-%s-%s", osName, arch), nil
+}
+
+// OfflineArtifacts lists the download artifacts an offline install of
+// Clojure, Babashka and a JDK needs on this platform
+func OfflineArtifacts() ([]string, error) {
+	artifacts := []string{"clojure-tools"}
+	for _, resolve := range []func() (string, error){babashkaArtifact, jdkArtifact} {
+		name, err := resolve()
+		if err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, name)
+	}
+	return artifacts, nil
+}
+
+// installJDKUser unpacks a JDK 17 tarball into ~/.local/share/hive and
+// links its tools into ~/.local/bin
+func installJDKUser() error {
+	artifact, err := jdkArtifact()
+	if err != nil {
+		return err
+	}
+	tarball, err := download.Fetch(artifact)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	dir := userJDKDir()
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := ExtractTarGz(f, dir, 1); err != nil {
+		return fmt.Errorf("failed to unpack JDK: %w", err)
+	}
+
+	// macOS bundles keep the JDK under Contents/Home
+	binDir := filepath.Join(dir, "bin")
+	if _, err := os.Stat(binDir); err != nil {
+		binDir = filepath.Join(dir, "Contents", "Home", "bin")
+	}
+	return linkIntoUserBin(binDir, "java", "javac", "jar")
+}
+
+// linkIntoUserBin symlinks the named executables from dir into ~/.local/bin
+func linkIntoUserBin(dir string, names ...string) error {
+	if err := os.MkdirAll(UserBinDir(), 0755); err != nil {
+		return err
+	}
+	for _, name := range names {
+		link := filepath.Join(UserBinDir(), name)
+		os.Remove(link)
+		if err := os.Symlink(filepath.Join(dir, name), link); err != nil {
+			return fmt.Errorf("failed to link %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// installClojureTools installs the Clojure CLI from the pinned
+// clojure-tools tarball for prefix, laid out as linux-install.sh does.
+// Files are written under dest, which is prefix unless staging.
+func installClojureTools(prefix, dest string) error {
+	tarball, err := download.Fetch("clojure-tools")
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	src, err := os.MkdirTemp("", "clojure-tools-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(src)
+	if err := ExtractTarGz(f, src, 1); err != nil {
+		return fmt.Errorf("failed to unpack clojure-tools: %w", err)
+	}
+
+	libDir := filepath.Join(prefix, "lib", "clojure")
+	binDir := filepath.Join(prefix, "bin")
+	manDir := filepath.Join(prefix, "share", "man", "man1")
+	// staged returns where a file for dir under prefix is written
+	staged := func(dir string) string {
+		rel, _ := filepath.Rel(prefix, dir)
+		return filepath.Join(dest, rel)
+	}
+	jars, _ := filepath.Glob(filepath.Join(src, "clojure-tools-*.jar"))
+
+	type toolFile struct {
+		name, dest string
+		mode       os.FileMode
+		old, new   string // placeholder substitution
+	}
+	files := []toolFile{
+		{"deps.edn", libDir, 0644, "", ""},
+		{"example-deps.edn", libDir, 0644, "", ""},
+		{"tools.edn", libDir, 0644, "", ""},
+		{"exec.jar", filepath.Join(libDir, "libexec"), 0644, "", ""},
+		{"clojure", binDir, 0755, "PREFIX", libDir},
+		{"clj", binDir, 0755, "BINDIR", binDir},
+		{"clojure.1", manDir, 0644, "", ""},
+		{"clj.1", manDir, 0644, "", ""},
+	}
+	for _, jar := range jars {
+		files = append(files, toolFile{filepath.Base(jar), filepath.Join(libDir, "libexec"), 0644, "", ""})
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(src, file.name))
+		if err != nil {
+			return fmt.Errorf("clojure-tools is missing %s: %w", file.name, err)
+		}
+		if file.old != "" {
+			data = []byte(strings.ReplaceAll(string(data), file.old, file.new))
+		}
+		dir := staged(file.dest)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, file.name), data, file.mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// installBabashkaBinary unpacks the pinned bb release into binDir
+func installBabashkaBinary(binDir string) error {
+	artifact, err := babashkaArtifact()
+	if err != nil {
+		return err
+	}
+	tarball, err := download.Fetch(artifact)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(tarball)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := os.MkdirAll(binDir, 0755); err != nil {
+		return err
+	}
+	return ExtractTarGz(f, binDir, 0)
+}
+
+// installAsRoot has write install files into dir: directly when
+// running as root, otherwise into a staging directory that is copied
+// into place with sudo, so only verified files are handled as root
+func installAsRoot(dir string, write func(dest string) error) error {
+	if os.Geteuid() == 0 {
+		return write(dir)
+	}
+
+	stage, err := os.MkdirTemp("", "hive-install-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stage)
+	if err := write(stage); err != nil {
+		return err
+	}
+	// cp applies the staging directory's mode to dir
+	if err := os.Chmod(stage, 0755); err != nil {
+		return err
+	}
+
+	cmd := exec.Command("sudo", "cp", "-R", stage+"/.", dir+"/")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to copy into %s: %w", dir, err)
+	}
+	return nil
+}
+
+// ExtractTarGz unpacks a gzipped tarball into dest, dropping the
+// first strip path components of every entry. Entries that would land
+// outside dest, symlinks pointing outside it and entries written
+// through a symlink are refused.
+func ExtractTarGz(r io.Reader, dest string, strip int) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		parts := strings.Split(strings.TrimPrefix(hdr.Name, "./"), "/")
+		if len(parts) <= strip {
+			continue
+		}
+		rel := filepath.Join(parts[strip:]...)
+		if rel == "." || rel == "" {
+			continue
+		}
+		target := filepath.Join(dest, rel)
+		if !insideDir(dest, target) {
+			return fmt.Errorf("unsafe path in archive: %s", hdr.Name)
+		}
+		if err := checkNoSymlinkParents(dest, rel); err != nil {
+			return fmt.Errorf("unsafe path in archive: %s: %w", hdr.Name, err)
+		}
+		// An existing link is replaced rather than written through
+		if info, err := os.Lstat(target); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) || !insideDir(dest, filepath.Join(filepath.Dir(target), hdr.Linkname)) {
+				return fmt.Errorf("unsafe symlink in archive: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			os.Remove(target)
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode)&0777)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// insideDir reports whether path is below dir
+func insideDir(dir, path string) bool {
+	return strings.HasPrefix(filepath.Clean(path), filepath.Clean(dir)+string(os.PathSeparator))
+}
+
+// checkNoSymlinkParents fails when a directory between dest and rel is
+// a symlink, which an earlier archive entry or an existing install
+// could point outside dest
+func checkNoSymlinkParents(dest, rel string) error {
+	dir := filepath.Clean(dest)
+	parts := strings.Split(filepath.Dir(rel), string(os.PathSeparator))
+	for _, part := range parts {
+		if part == "." {
+			continue
+		}
+		dir = filepath.Join(dir, part)
+		info, err := os.Lstat(dir)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%s is a symlink", dir)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,311 @@
+package setup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/download"
+)
+
+// tarGz builds a gzipped tarball of regular files
+func tarGz(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, body := range files {
+		hdr := &tar.Header{Name: name, Mode: 0o755, Size: int64(len(body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// fixtureMirror serves artifacts from a mirror directory, with their
+// checksums configured in a scratch config.yaml
+func fixtureMirror(t *testing.T, artifacts map[string][]byte) {
+	t.Helper()
+	home := t.TempDir()
+	mirror := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	t.Setenv("HIVE_DOWNLOAD_MIRROR", mirror)
+
+	var cfg strings.Builder
+	cfg.WriteString("downloads:\n  checksums:\n")
+	for name, data := range artifacts {
+		a, err := download.Lookup(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(mirror, a.FileName()), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		sum := sha256.Sum256(data)
+		fmt.Fprintf(&cfg, "    %s: %s\n", name, hex.EncodeToString(sum[:]))
+	}
+	dir := filepath.Join(home, ".config", "hive")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "config.yaml"), []byte(cfg.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInstallClojureUserFromMirror(t *testing.T) {
+	fixtureMirror(t, map[string][]byte{
+		"clojure-tools": tarGz(t, map[string]string{
+			"clojure-tools/deps.edn":                      "{}",
+			"clojure-tools/example-deps.edn":              "{}",
+			"clojure-tools/tools.edn":                     "{}",
+			"clojure-tools/exec.jar":                      "exec",
+			"clojure-tools/clojure-tools-1.12.0.1479.jar": "jar",
+			"clojure-tools/clojure":                       "install_dir=PREFIX\n",
+			"clojure-tools/clj":                           "bin_dir=BINDIR\n",
+			"clojure-tools/clojure.1":                     "man",
+			"clojure-tools/clj.1":                         "man",
+		}),
+	})
+
+	if err := installClojureUser(); err != nil {
+		t.Fatalf("installClojureUser: %v", err)
+	}
+
+	prefix := userPrefix()
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"bin/clojure", "install_dir=" + filepath.Join(prefix, "lib", "clojure") + "\n"},
+		{"bin/clj", "bin_dir=" + filepath.Join(prefix, "bin") + "\n"},
+		{"lib/clojure/deps.edn", "{}"},
+		{"lib/clojure/libexec/exec.jar", "exec"},
+		{"lib/clojure/libexec/clojure-tools-1.12.0.1479.jar", "jar"},
+		{"share/man/man1/clj.1", "man"},
+	}
+	for _, tt := range tests {
+		data, err := os.ReadFile(filepath.Join(prefix, tt.path))
+		if err != nil || string(data) != tt.want {
+			t.Errorf("%s = %q, %v; want %q", tt.path, data, err, tt.want)
+		}
+	}
+	if info, err := os.Stat(filepath.Join(prefix, "bin", "clojure")); err != nil || info.Mode()&0o111 == 0 {
+		t.Errorf("bin/clojure is not executable: %v", err)
+	}
+}
+
+func TestInstallBabashkaUserFromMirror(t *testing.T) {
+	artifact, err := babashkaArtifact()
+	if err != nil {
+		t.Skip(err)
+	}
+	fixtureMirror(t, map[string][]byte{
+		artifact: tarGz(t, map[string]string{"bb": "#!/bin/sh\necho bb\n"}),
+	})
+
+	if err := installBabashkaUser(); err != nil {
+		t.Fatalf("installBabashkaUser: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(UserBinDir(), "bb")); err != nil || !strings.Contains(string(data), "echo bb") {
+		t.Errorf("bb = %q, %v", data, err)
+	}
+}
+
+func TestInstallRefusesTamperedArtifact(t *testing.T) {
+	fixtureMirror(t, map[string][]byte{
+		"clojure-tools": tarGz(t, map[string]string{"clojure-tools/deps.edn": "{}"}),
+	})
+	a, err := download.Lookup("clojure-tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Swap the mirror copy after its checksum was configured
+	tampered := tarGz(t, map[string]string{"clojure-tools/clojure": "evil"})
+	if err := os.WriteFile(filepath.Join(os.Getenv("HIVE_DOWNLOAD_MIRROR"), a.FileName()), tampered, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	err = installClojureUser()
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("installClojureUser = %v, want checksum mismatch", err)
+	}
+	if _, err := os.Stat(filepath.Join(userPrefix(), "bin", "clojure")); err == nil {
+		t.Error("tampered clojure was installed")
+	}
+}
+
+// System installs are staged as the user with the final paths baked in
+func TestInstallClojureToolsStaged(t *testing.T) {
+	fixtureMirror(t, map[string][]byte{
+		"clojure-tools": tarGz(t, map[string]string{
+			"clojure-tools/deps.edn":         "{}",
+			"clojure-tools/example-deps.edn": "{}",
+			"clojure-tools/tools.edn":        "{}",
+			"clojure-tools/exec.jar":         "exec",
+			"clojure-tools/clojure":          "install_dir=PREFIX\n",
+			"clojure-tools/clj":              "bin_dir=BINDIR\n",
+			"clojure-tools/clojure.1":        "man",
+			"clojure-tools/clj.1":            "man",
+		}),
+	})
+
+	stage := t.TempDir()
+	if err := installClojureTools(systemPrefix, stage); err != nil {
+		t.Fatalf("installClojureTools: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(stage, "bin", "clojure"))
+	if want := "install_dir=/usr/local/lib/clojure\n"; err != nil || string(data) != want {
+		t.Errorf("staged bin/clojure = %q, %v; want %q", data, err, want)
+	}
+	if _, err := os.Stat(filepath.Join(stage, "lib", "clojure", "libexec", "exec.jar")); err != nil {
+		t.Errorf("exec.jar not staged: %v", err)
+	}
+}
+
+// tarEntry is one entry of a crafted archive
+type tarEntry struct {
+	name, body, link string
+	typ              byte
+}
+
+func craftedTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typ, Linkname: e.link}
+		if e.typ != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractTarGzRefusesEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries func(outside string) []tarEntry
+		// existing is a symlink created in dest before extracting
+		existing string
+	}{
+		{
+			name: "write through absolute symlink",
+			entries: func(outside string) []tarEntry {
+				return []tarEntry{
+					{name: "evil", link: outside, typ: tar.TypeSymlink},
+					{name: "evil/pwned", body: "x", typ: tar.TypeReg},
+				}
+			},
+		},
+		{
+			name: "relative symlink out of dest",
+			entries: func(outside string) []tarEntry {
+				return []tarEntry{{name: "lib/evil", link: "../../" + filepath.Base(outside), typ: tar.TypeSymlink}}
+			},
+		},
+		{
+			name: "parent path",
+			entries: func(string) []tarEntry {
+				return []tarEntry{{name: "../pwned", body: "x", typ: tar.TypeReg}}
+			},
+		},
+		{
+			name:     "existing symlinked directory",
+			existing: "dir",
+			entries: func(string) []tarEntry {
+				return []tarEntry{{name: "dir/pwned", body: "x", typ: tar.TypeReg}}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			dest := filepath.Join(root, "dest")
+			outside := filepath.Join(root, "outside")
+			for _, dir := range []string{dest, outside} {
+				if err := os.Mkdir(dir, 0o755); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.existing != "" {
+				if err := os.Symlink(outside, filepath.Join(dest, tt.existing)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := ExtractTarGz(bytes.NewReader(craftedTarGz(t, tt.entries(outside))), dest, 0)
+			if err == nil {
+				t.Fatal("ExtractTarGz accepted a malicious archive")
+			}
+			for _, p := range []string{filepath.Join(outside, "pwned"), filepath.Join(root, "pwned")} {
+				if _, err := os.Stat(p); err == nil {
+					t.Errorf("%s was written", p)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractTarGzLinks(t *testing.T) {
+	dest := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "target")
+	if err := os.WriteFile(outside, []byte("keep"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// A link left by an earlier install is replaced, not written through
+	if err := os.Symlink(outside, filepath.Join(dest, "bb")); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := craftedTarGz(t, []tarEntry{
+		{name: "legal/java.base/NOTICE", body: "notice", typ: tar.TypeReg},
+		{name: "legal/java.xml/NOTICE", link: "../java.base/NOTICE", typ: tar.TypeSymlink},
+		{name: "bb", body: "new", typ: tar.TypeReg},
+	})
+	if err := ExtractTarGz(bytes.NewReader(archive), dest, 0); err != nil {
+		t.Fatalf("ExtractTarGz: %v", err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dest, "legal", "java.xml", "NOTICE")); err != nil || string(data) != "notice" {
+		t.Errorf("internal symlink = %q, %v", data, err)
+	}
+	if data, _ := os.ReadFile(outside); string(data) != "keep" {
+		t.Errorf("file behind the old link = %q, want it untouched", data)
+	}
+	if info, err := os.Lstat(filepath.Join(dest, "bb")); err != nil || !info.Mode().IsRegular() {
+		t.Errorf("bb is not a regular file: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/detect"
+)
+
+// managerSpecs are the version requests passed to each manager, keyed
+// by the manager's plugin name
+var managerSpecs = map[string]string{
+	"java":     "temurin-17",
+	"clojure":  "latest",
+	"babashka": "latest",
+}
+
+// SupportedVersionManagers lists the managers setup can install through
+func SupportedVersionManagers() []string {
+	return []string{"mise", "asdf", "sdkman"}
+}
+
+// resolveVersionManager picks the manager named by the --version-manager
+// option for tool; "auto" selects the first detected one supporting it
+func resolveVersionManager(managers []detect.VersionManager, name, tool string) (detect.VersionManager, bool) {
+	for _, m := range managers {
+		if (name == "auto" || m.Name == name) && m.Supports(tool) {
+			return m, true
+		}
+	}
+	return detect.VersionManager{}, false
+}
+
+// installWithManager installs tool through a version manager and makes
+// it the user-wide default
+func installWithManager(m detect.VersionManager, tool string) error {
+	plugin := m.Plugin(tool)
+	spec := managerSpecs[plugin]
+
+	var err error
+	switch m.Name {
+	case "mise":
+		err = runManager(m.Path, "use", "--global", plugin+"@"+spec)
+	case "asdf":
+		err = installWithAsdf(m.Path, plugin, spec)
+	case "sdkman":
+		// sdk is a shell function; the default candidate is the latest LTS
+		cmd := exec.Command("bash", "-c", `source "$1/bin/sdkman-init.sh" && sdk install `+plugin, "sdk", m.Path)
+		cmd.Env = append(os.Environ(), "sdkman_auto_answer=true")
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		err = cmd.Run()
+	default:
+		return fmt.Errorf("unsupported version manager %q", m.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("%s install %s failed: %w", m.Name, plugin, err)
+	}
+
+	prependPath(managerBinDir(m, plugin))
+	return nil
+}
+
+// installWithAsdf adds the plugin if needed, installs the latest
+// version matching spec and sets it in ~/.tool-versions
+func installWithAsdf(asdf, plugin, spec string) error {
+	if out, err := exec.Command(asdf, "plugin", "list").Output(); err != nil || !hasLine(string(out), plugin) {
+		if err := runManager(asdf, "plugin", "add", plugin); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"latest", plugin}
+	if spec != "latest" {
+		args = append(args, spec)
+	}
+	out, err := exec.Command(asdf, args...).Output()
+	if err != nil {
+		return fmt.Errorf("no %s version matching %s: %w", plugin, spec, err)
+	}
+	version := strings.TrimSpace(string(out))
+
+	if err := runManager(asdf, "install", plugin, version); err != nil {
+		return err
+	}
+	// asdf 0.16 replaced "global" with "set --home"
+	if err := exec.Command(asdf, "set", "--home", plugin, version).Run(); err == nil {
+		return nil
+	}
+	return runManager(asdf, "global", plugin, version)
+}
+
+func runManager(path string, args ...string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func hasLine(out, want string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// managerBinDir is where a manager exposes the tools it installs
+func managerBinDir(m detect.VersionManager, plugin string) string {
+	home, _ := os.UserHomeDir()
+	switch m.Name {
+	case "mise":
+		data := os.Getenv("XDG_DATA_HOME")
+		if data == "" {
+			data = filepath.Join(home, ".local", "share")
+		}
+		return filepath.Join(data, "mise", "shims")
+	case "asdf":
+		data := os.Getenv("ASDF_DATA_DIR")
+		if data == "" {
+			data = filepath.Join(home, ".asdf")
+		}
+		return filepath.Join(data, "shims")
+	default:
+		return filepath.Join(m.Path, "candidates", plugin, "current", "bin")
+	}
+}
+
+// shimOwner returns the version manager a command on PATH belongs to,
+// or "" for system installs
+func shimOwner(command string) string {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return ""
+	}
+	path = filepath.ToSlash(path)
+	switch {
+	case strings.Contains(path, "/mise/"):
+		return "mise"
+	case strings.Contains(path, "/.asdf/"):
+		return "asdf"
+	case strings.Contains(path, "/.sdkman/"):
+		return "sdkman"
+	}
+	return ""
+}
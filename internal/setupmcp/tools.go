@@ -0,0 +1,283 @@
+// Package setupmcp exposes each setup step as its own MCP tool, so an
+// assistant can run setup one step at a time and recover from failures
+// instead of invoking the whole 'hive setup' command.
+package setupmcp
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// StepReport is the structured result of a setup_<step> tool
+type StepReport struct {
+	ID   string `json:"id"`
+	Tool string `json:"tool"`
+	setup.Result
+	// Changed is true when the step ran and succeeded
+	Changed bool `json:"changed"`
+	// Verified is true when the step's check passes after running
+	Verified bool   `json:"verified"`
+	DryRun   bool   `json:"dry_run,omitempty"`
+	Phase    string `json:"phase,omitempty"` // "check", "run" or "verify" when Error is set
+	Error    string `json:"error,omitempty"`
+	Output   string `json:"output,omitempty"`
+	// Next is the tool for the first later step that still needs running
+	Next string `json:"next,omitempty"`
+}
+
+// PlanStep is one entry of the setup_plan result
+type PlanStep struct {
+	ID    string `json:"id"`
+	Tool  string `json:"tool"`
+	Name  string `json:"name"`
+	Done  bool   `json:"done"`
+	Error string `json:"error,omitempty"`
+}
+
+// Plan is the structured result of setup_plan
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+	// Next is the tool for the first step that still needs running
+	Next string `json:"next,omitempty"`
+}
+
+// stepDescriptions are the tool descriptions, by step ID
+var stepDescriptions = map[string]string{
+	"clone":          "Clone the hive-mcp repository to ~/hive-mcp",
+	"shell":          "Add HIVE_MCP_DIR, BB_MCP_DIR and PATH to the shell config",
+	"prerequisites":  "Install the system prerequisites (Java, Clojure, Babashka, Emacs)",
+	"deps":           "Download the Clojure dependencies hive-mcp needs",
+	"emacs_packages": "Install the Emacs packages hive-mcp uses (doom sync on Doom Emacs)",
+	"emacs_config":   "Configure Emacs to load hive-mcp",
+	"chroma":         "Start the Chroma vector database in Docker",
+	"ollama":         "Set up Ollama with the nomic-embed-text embedding model",
+	"emacs_daemon":   "Start the Emacs daemon and wait until it answers",
+	"mcp":            "Register the hive-mcp server with Claude",
+}
+
+// mu serialises tool calls: they swap os.Stdout and share the
+// filesystem
+var mu sync.Mutex
+
+// Register adds setup_plan and one setup_<step> tool per setup step.
+// The tools already on s (the tagged hive commands, which also capture
+// os.Stdout and include setup and doctor --fix) are wrapped to take the
+// same lock.
+func Register(s *server.MCPServer) {
+	for _, tool := range s.ListTools() {
+		s.AddTool(tool.Tool, locked(tool.Handler))
+	}
+
+	s.AddTool(mcp.NewTool("setup_plan",
+		mcp.WithDescription("List the setup steps, which are already done, and the next tool to call"),
+		mcp.WithTitleAnnotation("Setup plan"),
+		mcp.WithReadOnlyHintAnnotation(true),
+		withOptionParams(),
+		mcp.WithOutputSchema[Plan](),
+	), planHandler)
+
+	for _, info := range setup.StepInfos() {
+		s.AddTool(mcp.NewTool(toolName(info.ID),
+			mcp.WithDescription(stepDescriptions[info.ID]+
+				". Skips the step when its check passes unless force is set."),
+			mcp.WithTitleAnnotation(info.Name),
+			mcp.WithReadOnlyHintAnnotation(false),
+			mcp.WithDestructiveHintAnnotation(false),
+			mcp.WithIdempotentHintAnnotation(true),
+			mcp.WithBoolean("force", mcp.Description("Run the step even if its check passes")),
+			mcp.WithBoolean("dry_run", mcp.Description("Only run the step's check")),
+			withOptionParams(),
+			mcp.WithOutputSchema[StepReport](),
+		), stepHandler(info.ID))
+	}
+}
+
+// withOptionParams adds the parameters that map to setup.Options
+func withOptionParams() mcp.ToolOption {
+	return func(t *mcp.Tool) {
+		mcp.WithBoolean("user_mode", mcp.Description("Install into ~/.local without sudo"))(t)
+		mcp.WithString("version_manager",
+			mcp.Description("Install Java/Clojure via a version manager: auto, "+
+				strings.Join(setup.SupportedVersionManagers(), ", ")))(t)
+		mcp.WithString("shell_mode",
+			mcp.Description("How the shell config exports variables: literal or eval"),
+			mcp.Enum(string(setup.ShellLiteral), string(setup.ShellEval)))(t)
+	}
+}
+
+// locked runs handler while holding mu
+func locked(handler server.ToolHandlerFunc) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return handler(ctx, req)
+	}
+}
+
+func toolName(id string) string {
+	return "setup_" + id
+}
+
+// loadSteps builds the steps from config.yaml and the request's options
+func loadSteps(req mcp.CallToolRequest) ([]setup.NamedStep, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	opts := setup.Options{
+		UserMode:       req.GetBool("user_mode", false),
+		VersionManager: req.GetString("version_manager", ""),
+		ShellMode:      setup.ShellMode(req.GetString("shell_mode", "")),
+	}
+	if err := opts.Validate(cfg); err != nil {
+		return nil, err
+	}
+	return setup.NewSteps(cfg, opts).List(), nil
+}
+
+func planHandler(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	steps, err := loadSteps(req)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var plan Plan
+	capture(func() {
+		for _, named := range steps {
+			entry := PlanStep{ID: named.ID, Tool: toolName(named.ID), Name: named.Step.Name()}
+			done, err := named.Step.Check()
+			entry.Done = done && err == nil
+			if err != nil {
+				entry.Error = err.Error()
+			}
+			if !entry.Done && plan.Next == "" {
+				plan.Next = entry.Tool
+			}
+			plan.Steps = append(plan.Steps, entry)
+		}
+	})
+	return mcp.NewToolResultStructuredOnly(plan), nil
+}
+
+func stepHandler(id string) server.ToolHandlerFunc {
+	return func(ctx context.Context, req mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		steps, err := loadSteps(req)
+		if err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		var report StepReport
+		report.Output = capture(func() {
+			report = runStep(steps, id, req.GetBool("force", false), req.GetBool("dry_run", false))
+		})
+
+		result := mcp.NewToolResultStructuredOnly(report)
+		result.IsError = report.Error != ""
+		return result, nil
+	}
+}
+
+// runStep checks and runs the step with the given ID, then works out
+// which step comes next
+func runStep(steps []setup.NamedStep, id string, force, dryRun bool) StepReport {
+	var report StepReport
+	for i, named := range steps {
+		if named.ID != id {
+			continue
+		}
+		step := named.Step
+		report = StepReport{
+			ID:     id,
+			Tool:   toolName(id),
+			Result: setup.Result{StepName: step.Name()},
+			DryRun: dryRun,
+		}
+
+		done, err := step.Check()
+		switch {
+		case err != nil && (dryRun || !force):
+			report.fail("check", err)
+			return report
+		case done && !force:
+			report.Skipped = true
+			report.Verified = true
+		case dryRun:
+		default:
+			if err := step.Run(); err != nil {
+				report.fail("run", err)
+				return report
+			}
+			report.Changed = true
+			verified, err := step.Check()
+			if err != nil {
+				report.fail("verify", err)
+				return report
+			}
+			report.Verified = verified
+		}
+
+		if report.Verified {
+			for _, later := range steps[i+1:] {
+				if done, err := later.Step.Check(); !done || err != nil {
+					report.Next = toolName(later.ID)
+					break
+				}
+			}
+		}
+		return report
+	}
+
+	report.ID = id
+	report.fail("check", fmt.Errorf("unknown setup step %q", id))
+	return report
+}
+
+func (r *StepReport) fail(phase string, err error) {
+	r.Result.Error = err
+	r.Phase = phase
+	r.Error = err.Error()
+}
+
+// capture runs fn with os.Stdout and os.Stderr redirected and returns
+// what it printed, interleaved. Installers can print more than a pipe
+// buffers, so the pipe is drained while fn runs.
+func capture(fn func()) (output string) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		fn()
+		return ""
+	}
+
+	var buf bytes.Buffer
+	copied := make(chan struct{})
+	go func() {
+		io.Copy(&buf, r)
+		close(copied)
+	}()
+
+	oldStdout, oldStderr := os.Stdout, os.Stderr
+	os.Stdout, os.Stderr = w, w
+	defer func() {
+		os.Stdout, os.Stderr = oldStdout, oldStderr
+		w.Close()
+		<-copied
+		r.Close()
+		output = buf.String()
+	}()
+	fn()
+	return ""
+}
@@ -0,0 +1,80 @@
+package setupmcp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// fakeStep reports done after it has run, unless checkErr is set
+type fakeStep struct {
+	ran      bool
+	runErr   error
+	checkErr error
+}
+
+func (s *fakeStep) Name() string { return "fake" }
+
+func (s *fakeStep) Check() (bool, error) {
+	if s.ran && s.checkErr != nil {
+		return false, s.checkErr
+	}
+	return s.ran, nil
+}
+
+func (s *fakeStep) Run() error {
+	fmt.Println("running")
+	s.ran = true
+	return s.runErr
+}
+
+func (s *fakeStep) Rollback() error { return nil }
+
+func TestRunStep(t *testing.T) {
+	tests := []struct {
+		name     string
+		step     *fakeStep
+		phase    string
+		changed  bool
+		verified bool
+		next     string
+	}{
+		{"runs", &fakeStep{}, "", true, true, "setup_later"},
+		{"run fails", &fakeStep{runErr: errors.New("boom")}, "run", false, false, ""},
+		{"verify fails", &fakeStep{checkErr: errors.New("daemon gone")}, "verify", true, false, ""},
+		{"already done", &fakeStep{ran: true}, "", false, true, "setup_later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			steps := []setup.NamedStep{{ID: "fake", Step: tt.step}, {ID: "later", Step: &fakeStep{}}}
+			report := runStep(steps, "fake", false, false)
+			if report.Phase != tt.phase || report.Changed != tt.changed ||
+				report.Verified != tt.verified || report.Next != tt.next {
+				t.Errorf("report = %+v", report)
+			}
+			if (report.Error != "") != (tt.phase != "") {
+				t.Errorf("Error = %q for phase %q", report.Error, tt.phase)
+			}
+		})
+	}
+}
+
+func TestRunStepUnknown(t *testing.T) {
+	report := runStep(nil, "nope", false, false)
+	if report.Phase != "check" || report.Error == "" {
+		t.Errorf("report = %+v, want an unknown step error", report)
+	}
+}
+
+func TestCaptureStderr(t *testing.T) {
+	out := capture(func() {
+		fmt.Fprint(os.Stdout, "out ")
+		fmt.Fprint(os.Stderr, "err")
+	})
+	if out != "out err" {
+		t.Errorf("capture = %q, want stdout and stderr", out)
+	}
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/briandowns/spinner"
 	"github.com/fatih/color"
+	"golang.org/x/term"
 )
 
 // Colors for consistent output styling
@@ -92,13 +93,13 @@ func (sp *Spinner) Stop() {
 // Success stops the spinner and shows success message
 func (sp *Spinner) Success(message string) {
 	sp.s.Stop()
-	PrintSuccess(message)
+	PrintSuccess("%s", message)
 }
 
 // Fail stops the spinner and shows error message
 func (sp *Spinner) Fail(message string) {
 	sp.s.Stop()
-	PrintError(message)
+	PrintError("%s", message)
 }
 
 // UpdateMessage changes the spinner message
@@ -122,3 +123,19 @@ func ConfirmPrompt(prompt string) bool {
 	fmt.Scanln(&response)
 	return response == "y" || response == "Y" || response == "yes" || response == "Yes"
 }
+
+// IsTerminal reports whether stdin is an interactive terminal
+func IsTerminal() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// PasswordPrompt reads a line from the terminal without echoing it
+func PasswordPrompt(prompt string) (string, error) {
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	value, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
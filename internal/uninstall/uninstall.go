@@ -0,0 +1,175 @@
+// Package uninstall removes what setup installed by running the setup
+// steps' rollbacks in a controlled order
+package uninstall
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/chroma"
+	"github.com/hive-agi/hive-mcp-cli/internal/emacs"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/services"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// Options configures Plan
+type Options struct {
+	HiveMCPDir     string
+	Only           []string // restrict to these components, empty means all
+	PurgeData      bool     // also delete the Chroma data volume
+	RemoveCheckout bool     // also delete the hive-mcp checkout
+}
+
+// Component is a piece of the installation uninstall can remove
+type Component struct {
+	Name    string // selector used with --only
+	Summary string // what removing it does, shown before confirming
+	Remove  func() error
+}
+
+// Components lists the selectable component names in removal order.
+// "data" and "checkout" are only removed when asked for.
+func Components() []string {
+	return []string{"mcp", "shell", "emacs-config", "units", "emacs", "chroma", "data", "checkout"}
+}
+
+// Plan returns the components that are installed and selected, in the
+// order they should be removed. Nothing is changed.
+func Plan(opts Options) ([]Component, error) {
+	for _, name := range opts.Only {
+		if !slices.Contains(Components(), name) {
+			return nil, fmt.Errorf("unknown component %q (expected one of: %s)", name, strings.Join(Components(), ", "))
+		}
+	}
+	if opts.HiveMCPDir == "" {
+		opts.HiveMCPDir = util.ExpandPath(util.GetEnv("HIVE_MCP_DIR", setup.DefaultHiveMCPDir()))
+	}
+
+	var plan []Component
+	add := func(c Component) {
+		if opts.selects(c.Name) {
+			plan = append(plan, c)
+		}
+	}
+
+	mcp := &setup.MCPStep{HiveMCPDir: opts.HiveMCPDir}
+	if ok, _ := mcp.Check(); ok {
+		add(Component{
+			Name:    "mcp",
+			Summary: "Remove the 'emacs' MCP server from Claude CLI",
+			Remove:  mcp.Rollback,
+		})
+	}
+
+	if files := setup.ShellBlockFiles(); len(files) > 0 {
+		add(Component{
+			Name:    "shell",
+			Summary: "Strip the managed block from " + strings.Join(files, ", "),
+			Remove:  (&setup.ShellStep{}).Rollback,
+		})
+	}
+
+	emacsConfig := &setup.EmacsConfigStep{HiveMCPDir: opts.HiveMCPDir}
+	if emacsConfig.HasBlock() {
+		add(Component{
+			Name:    "emacs-config",
+			Summary: "Strip the managed block from " + emacsConfig.File(),
+			Remove:  emacsConfig.Rollback,
+		})
+	}
+
+	if units := services.InstalledUnits(); len(units) > 0 {
+		add(Component{
+			Name:    "units",
+			Summary: "Disable and delete systemd units " + strings.Join(units, ", "),
+			Remove:  func() error { return services.RemoveUnits(units) },
+		})
+	}
+
+	if (&services.Emacs{}).Status().Running {
+		server := emacs.Server()
+		add(Component{
+			Name:    "emacs",
+			Summary: "Stop the Emacs daemon (" + emacs.DisplayName(server) + ")",
+			Remove:  (&setup.EmacsDaemonStep{ServerName: server}).Rollback,
+		})
+	}
+
+	if services.HasChromaContainer() {
+		add(Component{
+			Name:    "chroma",
+			Summary: "Stop and remove the Chroma container (data volume is kept)",
+			Remove:  services.RemoveChroma,
+		})
+	}
+
+	if volume, err := chroma.ResolveVolume(""); err == nil {
+		add(Component{
+			Name:    "data",
+			Summary: "Delete the Chroma data volume " + volume + " (memories and embeddings)",
+			Remove:  func() error { return removeVolume(volume) },
+		})
+	}
+
+	if util.DirExists(opts.HiveMCPDir) {
+		summary := "Delete the hive-mcp checkout " + opts.HiveMCPDir
+		if out, err := exec.Command("git", "-C", opts.HiveMCPDir, "status", "--porcelain").Output(); err == nil && len(out) > 0 {
+			summary += " (has uncommitted changes)"
+		}
+		add(Component{
+			Name:    "checkout",
+			Summary: summary,
+			Remove:  (&setup.CloneStep{HiveMCPDir: opts.HiveMCPDir}).Rollback,
+		})
+	}
+
+	return plan, nil
+}
+
+// selects reports whether the named component is to be removed
+func (opts Options) selects(name string) bool {
+	if len(opts.Only) > 0 {
+		// The data volume can't be deleted while the container uses it
+		return slices.Contains(opts.Only, name) ||
+			name == "chroma" && slices.Contains(opts.Only, "data")
+	}
+	switch name {
+	case "data":
+		return opts.PurgeData
+	case "checkout":
+		return opts.RemoveCheckout
+	}
+	return true
+}
+
+// Remove removes the components in order, continuing past failures so
+// one broken piece doesn't leave the rest installed
+func Remove(plan []Component) error {
+	var failed []string
+	for _, c := range plan {
+		fmt.Printf("→ %s\n", c.Summary)
+		if err := c.Remove(); err != nil {
+			fmt.Printf("  ✗ %v\n", err)
+			failed = append(failed, c.Name)
+			continue
+		}
+		fmt.Println("  ✓ done")
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to remove: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func removeVolume(volume string) error {
+	cmd := exec.Command("docker", "volume", "rm", volume)
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to remove volume %s: %w", volume, err)
+	}
+	return nil
+}
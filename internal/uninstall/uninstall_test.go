@@ -0,0 +1,79 @@
+package uninstall
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanRejectsUnknownComponent(t *testing.T) {
+	_, err := Plan(Options{Only: []string{"shell", "volumes"}})
+	if err == nil || !strings.Contains(err.Error(), `unknown component "volumes"`) {
+		t.Fatalf("Plan = %v, want an unknown component error", err)
+	}
+}
+
+func TestSelects(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"default", Options{}, []string{"mcp", "shell", "emacs-config", "units", "emacs", "chroma"}},
+		{"purge data", Options{PurgeData: true}, []string{"mcp", "shell", "emacs-config", "units", "emacs", "chroma", "data"}},
+		{"remove checkout", Options{RemoveCheckout: true}, []string{"mcp", "shell", "emacs-config", "units", "emacs", "chroma", "checkout"}},
+		{"only", Options{Only: []string{"shell", "mcp"}}, []string{"mcp", "shell"}},
+		{"only data pulls in chroma", Options{Only: []string{"data"}}, []string{"chroma", "data"}},
+		{"only checkout", Options{Only: []string{"checkout"}}, []string{"checkout"}},
+		{"only ignores the flags", Options{Only: []string{"emacs"}, PurgeData: true, RemoveCheckout: true}, []string{"emacs"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, name := range Components() {
+				if tt.opts.selects(name) {
+					got = append(got, name)
+				}
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("selected %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// With none of the external tools on PATH, only the checkout is found
+func TestPlanCheckout(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("PATH", t.TempDir())
+	checkout := t.TempDir()
+
+	for _, opts := range []Options{
+		{HiveMCPDir: checkout},
+		{HiveMCPDir: checkout, PurgeData: true},
+		{HiveMCPDir: checkout, Only: []string{"shell"}},
+	} {
+		plan, err := Plan(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan) != 0 {
+			t.Errorf("Plan(%+v) = %v, want nothing", opts, plan)
+		}
+	}
+
+	for _, opts := range []Options{
+		{HiveMCPDir: checkout, RemoveCheckout: true},
+		{HiveMCPDir: checkout, Only: []string{"checkout"}},
+	} {
+		plan, err := Plan(opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(plan) != 1 || plan[0].Name != "checkout" || !strings.Contains(plan[0].Summary, checkout) {
+			t.Errorf("Plan(%+v) = %v, want the checkout", opts, plan)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+// Package update upgrades an existing hive-mcp checkout to its
+// configured ref and re-runs only the setup steps the change affects
+package update
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/hive-agi/hive-mcp-cli/internal/config"
+	"github.com/hive-agi/hive-mcp-cli/internal/doctor"
+	"github.com/hive-agi/hive-mcp-cli/internal/pkg/util"
+	"github.com/hive-agi/hive-mcp-cli/internal/setup"
+)
+
+// Options configures Update
+type Options struct {
+	HiveMCPDir string
+	Ref        string // overrides repo.ref from the config
+	Stash      bool   // stash local changes instead of refusing
+	DryRun     bool   // show incoming commits and affected steps only
+}
+
+// Plan describes an update before it is applied
+type Plan struct {
+	From     string // current commit
+	To       string // target commit
+	Target   string // ref or upstream being followed
+	Branch   string // branch to fast-forward, "" for a detached checkout
+	Incoming []string
+	Changed  []string
+
+	DepsChanged   bool // deps.edn: re-run clojure -P
+	ElispChanged  bool // *.el: re-run doom sync
+	LaunchChanged bool // bb.edn: re-register the MCP server
+
+	prevBranch string // branch checked out before the update
+	branchTip  string // Branch's commit before the update, "" if new
+}
+
+// UpToDate reports whether the checkout is already at the target
+func (p *Plan) UpToDate() bool {
+	return p.From == p.To && (p.Branch == "" || p.Branch == p.prevBranch)
+}
+
+// ErrDirty is returned when the checkout has uncommitted changes
+var ErrDirty = errors.New("working tree has local changes; commit them or rerun with --stash")
+
+// Update fetches the checkout's remote and moves it to the configured
+// ref, then re-runs the affected setup steps. If doctor reports errors
+// that weren't present before the update, the checkout is rolled back
+// to the previous commit.
+func Update(opts Options) (*Plan, error) {
+	if opts.HiveMCPDir == "" {
+		opts.HiveMCPDir = util.ExpandPath(util.GetEnv("HIVE_MCP_DIR", setup.DefaultHiveMCPDir()))
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	ref := cfg.Repo.Ref
+	if opts.Ref != "" {
+		ref = opts.Ref
+	}
+
+	dir := opts.HiveMCPDir
+	co, err := setup.ReadCheckout(dir)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Repo.URL != "" && !setup.SameRemote(co.Remote, cfg.Repo.URL) {
+		return nil, fmt.Errorf("checkout at %s has origin %s, config expects %s", dir, co.Remote, cfg.Repo.URL)
+	}
+
+	dirty, err := git(dir, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return nil, err
+	}
+	if dirty != "" && !opts.Stash && !opts.DryRun {
+		return nil, ErrDirty
+	}
+
+	fmt.Printf("Fetching %s...\n", co.Remote)
+	if err := runGit(dir, "fetch", "--tags", "origin"); err != nil {
+		return nil, fmt.Errorf("git fetch failed: %w", err)
+	}
+
+	plan, err := resolve(dir, co, ref)
+	if err != nil {
+		return nil, err
+	}
+	if plan.UpToDate() {
+		return plan, nil
+	}
+	if err := plan.describe(dir); err != nil {
+		return nil, err
+	}
+	plan.print()
+	if opts.DryRun {
+		return plan, nil
+	}
+
+	if dirty != "" {
+		fmt.Println("Stashing local changes...")
+		if err := runGit(dir, "stash", "push", "-m", "hive update "+time.Now().Format(time.RFC3339)); err != nil {
+			return nil, fmt.Errorf("git stash failed: %w", err)
+		}
+		defer func() {
+			fmt.Println("Restoring stashed changes...")
+			if err := runGit(dir, "stash", "pop"); err != nil {
+				fmt.Println("  ! git stash pop failed; your changes are still in 'git stash list'")
+			}
+		}()
+	}
+
+	before, err := doctor.RunAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := plan.apply(dir); err != nil {
+		return nil, err
+	}
+	stepErr := plan.runSteps(dir)
+
+	after, err := doctor.RunAll()
+	if err != nil {
+		return nil, err
+	}
+	regressions := newFailures(before, after)
+	if stepErr == nil && len(regressions) == 0 {
+		return plan, nil
+	}
+
+	// Roll back to the previous commit and restore its state
+	if stepErr != nil {
+		regressions = append(regressions, stepErr.Error())
+	}
+	fmt.Printf("\nUpdate failed checks, rolling back to %.12s...\n", plan.From)
+	if err := plan.rollback(dir); err != nil {
+		return nil, fmt.Errorf("rollback failed: %w", err)
+	}
+	if err := plan.runSteps(dir); err != nil {
+		return nil, fmt.Errorf("rolled back to %.12s but re-running setup steps failed: %w", plan.From, err)
+	}
+	return nil, fmt.Errorf("update rolled back: %s", strings.Join(regressions, "; "))
+}
+
+// resolve finds the commit the checkout should move to. A branch
+// follows origin/<branch>; a tag or SHA is checked out detached. With
+// no ref, the current branch follows its upstream.
+func resolve(dir string, co *setup.Checkout, ref string) (*Plan, error) {
+	plan := &Plan{From: co.Commit}
+	plan.prevBranch, _ = git(dir, "symbolic-ref", "--short", "-q", "HEAD")
+
+	switch {
+	case ref == "":
+		if plan.prevBranch == "" {
+			return nil, fmt.Errorf("checkout is detached at %s; set repo.ref or pass --ref", co.Ref)
+		}
+		upstream, err := git(dir, "rev-parse", "--abbrev-ref", "@{upstream}")
+		if err != nil {
+			return nil, fmt.Errorf("branch %s has no upstream; set repo.ref or pass --ref", plan.prevBranch)
+		}
+		plan.Target, plan.Branch = upstream, plan.prevBranch
+	case exists(dir, "refs/remotes/origin/"+ref):
+		plan.Target, plan.Branch = "origin/"+ref, ref
+	default:
+		if !exists(dir, ref+"^{commit}") {
+			// A commit not reachable from any fetched ref
+			if err := runGit(dir, "fetch", "origin", ref); err != nil {
+				return nil, fmt.Errorf("ref %s not found on origin", ref)
+			}
+		}
+		plan.Target = ref
+	}
+
+	to, err := git(dir, "rev-parse", plan.Target+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", plan.Target, err)
+	}
+	plan.To = to
+
+	if plan.Branch != "" {
+		plan.branchTip, _ = git(dir, "rev-parse", "-q", "--verify", "refs/heads/"+plan.Branch)
+		if plan.branchTip != "" && exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", plan.branchTip, plan.To).Run() != nil {
+			return nil, fmt.Errorf("branch %s has diverged from %s; refusing to update a non-fast-forward", plan.Branch, plan.Target)
+		}
+	}
+	return plan, nil
+}
+
+// describe fills in the incoming commits and changed files
+func (p *Plan) describe(dir string) error {
+	log, err := git(dir, "log", "--oneline", "--no-decorate", p.From+".."+p.To)
+	if err != nil {
+		return err
+	}
+	if log != "" {
+		p.Incoming = strings.Split(log, "\n")
+	}
+
+	files, err := git(dir, "diff", "--name-only", p.From, p.To)
+	if err != nil {
+		return err
+	}
+	if files == "" {
+		return nil
+	}
+	p.Changed = strings.Split(files, "\n")
+	for _, f := range p.Changed {
+		switch base := path.Base(f); {
+		case base == "deps.edn":
+			p.DepsChanged = true
+		case base == "bb.edn":
+			p.LaunchChanged = true
+		case strings.HasSuffix(base, ".el"):
+			p.ElispChanged = true
+		}
+	}
+	return nil
+}
+
+func (p *Plan) print() {
+	fmt.Printf("\nUpdating %.12s -> %.12s (%s)\n", p.From, p.To, p.Target)
+	if len(p.Incoming) == 0 {
+		fmt.Println("  No new commits (moving to an older or unrelated ref)")
+	} else {
+		fmt.Printf("  %d incoming commit(s):\n", len(p.Incoming))
+		for _, c := range p.Incoming {
+			fmt.Printf("    %s\n", c)
+		}
+	}
+
+	fmt.Println("  Steps to re-run:")
+	steps := p.steps("")
+	if len(steps) == 0 {
+		fmt.Println("    none")
+	}
+	for _, s := range steps {
+		fmt.Printf("    %s\n", s.Name())
+	}
+	fmt.Println()
+}
+
+// apply fast-forwards the branch or checks out the target detached.
+// The tree is clean (or stashed), so nothing is lost.
+func (p *Plan) apply(dir string) error {
+	if p.Branch != "" {
+		if err := runGit(dir, "checkout", "-B", p.Branch, p.To); err != nil {
+			return fmt.Errorf("failed to move %s to %.12s: %w", p.Branch, p.To, err)
+		}
+		return nil
+	}
+	if err := runGit(dir, "checkout", "--detach", p.To); err != nil {
+		return fmt.Errorf("failed to check out %.12s: %w", p.To, err)
+	}
+	return nil
+}
+
+// rollback restores the checkout and branch as they were before apply
+func (p *Plan) rollback(dir string) error {
+	var err error
+	if p.prevBranch != "" {
+		err = runGit(dir, "checkout", "-B", p.prevBranch, p.From)
+	} else {
+		err = runGit(dir, "checkout", "--detach", p.From)
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.Branch == "" || p.Branch == p.prevBranch {
+		return nil
+	}
+	if p.branchTip == "" {
+		return runGit(dir, "branch", "-D", p.Branch)
+	}
+	return runGit(dir, "branch", "-f", p.Branch, p.branchTip)
+}
+
+// steps returns the setup steps affected by the change
+func (p *Plan) steps(dir string) []setup.Step {
+	var steps []setup.Step
+	if p.DepsChanged {
+		steps = append(steps, forced{&setup.CloneDepsStep{HiveMCPDir: dir}})
+	}
+	if p.ElispChanged && setup.DoomInstalled() {
+		steps = append(steps, forced{&setup.DoomSyncStep{}})
+	}
+	if p.LaunchChanged {
+		steps = append(steps, reregister{&setup.MCPStep{HiveMCPDir: dir}})
+	}
+	return steps
+}
+
+func (p *Plan) runSteps(dir string) error {
+	steps := p.steps(dir)
+	if len(steps) == 0 {
+		return nil
+	}
+	return setup.NewRunner(steps).RunAll()
+}
+
+// forced runs a step even when its Check reports it done
+type forced struct {
+	setup.Step
+}
+
+func (f forced) Check() (bool, error) {
+	return false, nil
+}
+
+// reregister replaces the existing MCP registration
+type reregister struct {
+	*setup.MCPStep
+}
+
+func (r reregister) Name() string {
+	return "Re-register MCP server with Claude CLI"
+}
+
+func (r reregister) Check() (bool, error) {
+	return false, nil
+}
+
+func (r reregister) Run() error {
+	// Removing fails harmlessly when nothing is registered
+	r.MCPStep.Rollback()
+	return r.MCPStep.Run()
+}
+
+// newFailures returns the checks failing after the update that
+// weren't failing before it
+func newFailures(before, after *doctor.DoctorResult) []string {
+	failing := make(map[string]bool)
+	for _, cat := range before.Categories {
+		for _, check := range cat.Checks {
+			if check.Status == doctor.StatusError {
+				failing[cat.Name+"/"+check.Name] = true
+			}
+		}
+	}
+
+	var regressions []string
+	for _, cat := range after.Categories {
+		for _, check := range cat.Checks {
+			if check.Status == doctor.StatusError && !failing[cat.Name+"/"+check.Name] {
+				regressions = append(regressions, fmt.Sprintf("%s: %s", check.Name, check.Message))
+			}
+		}
+	}
+	return regressions
+}
+
+func exists(dir, rev string) bool {
+	return exec.Command("git", "-C", dir, "rev-parse", "-q", "--verify", rev).Run() == nil
+}
+
+func git(dir string, args ...string) (string, error) {
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func runGit(dir string, args ...string) error {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
@@ -0,0 +1,20 @@
+#!/usr/bin/env bash
+# Recompute the sha256 column of internal/download/artifacts.txt by
+# downloading every pinned artifact. Review the diff before committing.
+set -euo pipefail
+
+manifest="$(dirname "$0")/../internal/download/artifacts.txt"
+tmp=$(mktemp)
+trap 'rm -f "$tmp"' EXIT
+
+while IFS= read -r line; do
+  if [[ -z "$line" || "$line" == \#* ]]; then
+    echo "$line"
+    continue
+  fi
+  read -r name version url _ <<<"$line"
+  sum=$(curl -fsSL "$url" | sha256sum | cut -d' ' -f1)
+  printf '%-23s %-12s %s  %s\n' "$name" "$version" "$url" "$sum"
+done <"$manifest" >"$tmp"
+
+mv "$tmp" "$manifest"